@@ -1,18 +1,55 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
 
 func main() {
-	lines := openInputFile()
-	numSubsets := evaluatePart(lines, EvaluateSubset)
-	log.Printf("Part 1: There are %v elf pairings in which the range that one elf is cleaning contains the entire range that the paired elf is cleaning", numSubsets)
-	numIntersections := evaluatePart(lines, EvaluateIntersection)
-	log.Printf("Part 2: There are %v elf pairings that have intersection assigments", numIntersections)
+	overlapNames := flag.String("overlap", "", fmt.Sprintf("comma-separated list of overlap funcs to evaluate, one of: %v or %v", strings.Join(OverlapFuncNames(), ", "), OVERLAP_SPEC_USAGE))
+	definitions := flag.String("define", "", "comma-separated list of name="+OVERLAP_SPEC_USAGE+" overlap funcs to register for -overlap, EX loose=any:intersection")
+	flag.Parse()
+
+	if *definitions != "" {
+		for _, definition := range strings.Split(*definitions, ",") {
+			name, spec, found := strings.Cut(definition, "=")
+			if !found {
+				log.Fatalf("invalid overlap func definition %q, expected name=%v", definition, OVERLAP_SPEC_USAGE)
+			}
+			overlapFunc, err := ParseOverlapSpec(spec)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if err := RegisterOverlapFunc(name, overlapFunc); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	elfGroups, err := parseElfGroups(openInputFile())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *overlapNames == "" {
+		numSubsets := evaluatePart(elfGroups, AnyPair(EvaluateSubset))
+		log.Printf("Part 1: There are %v elf pairings in which the range that one elf is cleaning contains the entire range that the paired elf is cleaning", numSubsets)
+		numIntersections := evaluatePart(elfGroups, AnyPair(EvaluateIntersection))
+		log.Printf("Part 2: There are %v elf pairings that have intersection assigments", numIntersections)
+		return
+	}
+
+	for _, name := range strings.Split(*overlapNames, ",") {
+		overlapFunc, err := LookupOverlapFunc(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("%v: There are %v elf groups that satisfy the overlap func", name, evaluatePart(elfGroups, overlapFunc))
+	}
 }
 
 func openInputFile() []string {
@@ -23,24 +60,64 @@ func openInputFile() []string {
 	return strings.Split(string(data), "\n")
 }
 
-func evaluatePart(lines []string, overlapFunc OverlapFunc) int {
+/*
+Parses every non-empty line into the assignments of an elf group
+*/
+func parseElfGroups(lines []string) ([][]ElfAssignment, error) {
+	elfGroups := [][]ElfAssignment{}
+	for i, elfGroup := range lines {
+		if elfGroup == "" {
+			continue
+		}
+		assignments, err := ParseElfAssignments(elfGroup)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %w", i+1, err)
+		}
+		elfGroups = append(elfGroups, assignments)
+	}
+	return elfGroups, nil
+}
+
+func evaluatePart(elfGroups [][]ElfAssignment, overlapFunc GroupOverlapFunc) int {
 	var numOverlaps int
-	for _, elfPairings := range lines {
-		assignment1, assignment2 := ParseElfAssignments(elfPairings)
-		if overlapFunc(assignment1, assignment2) {
+	for _, assignments := range elfGroups {
+		if overlapFunc(assignments) {
 			numOverlaps++
 		}
 	}
 	return numOverlaps
 }
 
-func ParseElfAssignments(elfPairings string) (ElfAssignment, ElfAssignment) {
-	assignments := strings.Split(elfPairings, ",")
-	return ElfAssignment{assignments[0]}, ElfAssignment{assignments[1]}
+/*
+Parses a line of any number of comma-separated section ranges into the assignments of an elf group
+*/
+func ParseElfAssignments(elfGroup string) ([]ElfAssignment, error) {
+	assignments := []ElfAssignment{}
+	for _, sections := range strings.Split(elfGroup, ",") {
+		lowStr, highStr, found := strings.Cut(sections, "-")
+		low, lowErr := strconv.Atoi(lowStr)
+		high, highErr := strconv.Atoi(highStr)
+		if !found || lowErr != nil || highErr != nil {
+			return nil, fmt.Errorf("invalid section range %q, expected LOW-HIGH", sections)
+		}
+		if low > high {
+			return nil, fmt.Errorf("invalid section range %q, the range ends before it starts", sections)
+		}
+		assignments = append(assignments, ElfAssignment{low, high})
+	}
+	return assignments, nil
 }
 
+/*
+OverlapFunc compares the assignments of two elves
+*/
 type OverlapFunc func(ElfAssignment, ElfAssignment) bool
 
+/*
+GroupOverlapFunc evaluates the assignments of an entire elf group
+*/
+type GroupOverlapFunc func([]ElfAssignment) bool
+
 func EvaluateSubset(ea1, ea2 ElfAssignment) bool {
 	return ea1.IsSubsetOfPairedElf(ea2) || ea2.IsSubsetOfPairedElf(ea1)
 }
@@ -49,15 +126,160 @@ func EvaluateIntersection(ea1, ea2 ElfAssignment) bool {
 	return ea1.Intersects(ea2) || ea2.Intersects(ea1)
 }
 
+/*
+Aggregates an OverlapFunc so that the group satisfies it if any pair of elves in the group does
+*/
+func AnyPair(overlapFunc OverlapFunc) GroupOverlapFunc {
+	return func(assignments []ElfAssignment) bool {
+		for i := 0; i < len(assignments); i++ {
+			for j := i + 1; j < len(assignments); j++ {
+				if overlapFunc(assignments[i], assignments[j]) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+/*
+Aggregates an OverlapFunc so that the group satisfies it only if every pair of elves in the group does
+*/
+func AllPairs(overlapFunc OverlapFunc) GroupOverlapFunc {
+	return func(assignments []ElfAssignment) bool {
+		for i := 0; i < len(assignments); i++ {
+			for j := i + 1; j < len(assignments); j++ {
+				if !overlapFunc(assignments[i], assignments[j]) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+}
+
+/*
+A group shares a common section if there is at least one section that every elf in the group is cleaning
+*/
+func EvaluateCommonSection(assignments []ElfAssignment) bool {
+	if len(assignments) == 0 {
+		return false
+	}
+	low, high := assignments[0].GetRange()
+	for _, assignment := range assignments[1:] {
+		otherLow, otherHigh := assignment.GetRange()
+		if otherLow > low {
+			low = otherLow
+		}
+		if otherHigh < high {
+			high = otherHigh
+		}
+	}
+	return low <= high
+}
+
+/*
+A group is contained by one elf if the range of some elf contains the ranges of all other elves in the group
+*/
+func EvaluateContainsAll(assignments []ElfAssignment) bool {
+	for i, container := range assignments {
+		containsAll := true
+		for j, other := range assignments {
+			if i != j && !other.IsSubsetOfPairedElf(container) {
+				containsAll = false
+				break
+			}
+		}
+		if containsAll {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+Registry of named GroupOverlapFuncs that can be selected from the CLI, more can be registered w/ RegisterOverlapFunc
+*/
+var overlapFuncs = map[string]GroupOverlapFunc{
+	"any-subset":       AnyPair(EvaluateSubset),
+	"any-intersection": AnyPair(EvaluateIntersection),
+	"all-subset":       AllPairs(EvaluateSubset),
+	"all-intersection": AllPairs(EvaluateIntersection),
+	"common-section":   EvaluateCommonSection,
+	"contains-all":     EvaluateContainsAll,
+}
+
+/*
+Registers a custom GroupOverlapFunc under the given name, names must be unique
+*/
+func RegisterOverlapFunc(name string, overlapFunc GroupOverlapFunc) error {
+	if _, exists := overlapFuncs[name]; exists {
+		return fmt.Errorf("overlap func %q is already registered", name)
+	}
+	overlapFuncs[name] = overlapFunc
+	return nil
+}
+
+/*
+Returns the overlap func registered under name, names that aren't registered are parsed as an overlap spec
+*/
+func LookupOverlapFunc(name string) (GroupOverlapFunc, error) {
+	if overlapFunc, exists := overlapFuncs[name]; exists {
+		return overlapFunc, nil
+	}
+	if _, _, found := strings.Cut(name, ":"); found {
+		return ParseOverlapSpec(name)
+	}
+	return nil, fmt.Errorf("unknown overlap func %q, expected one of: %v or %v", name, strings.Join(OverlapFuncNames(), ", "), OVERLAP_SPEC_USAGE)
+}
+
+const OVERLAP_SPEC_USAGE = "any:PAIR or all:PAIR w/ PAIR one of subset, intersection"
+
+/*
+OverlapFuncs between two elves that can be aggregated over a group w/ an overlap spec
+*/
+var pairOverlapFuncs = map[string]OverlapFunc{
+	"subset":       EvaluateSubset,
+	"intersection": EvaluateIntersection,
+}
+
+/*
+Parses an overlap spec such as "all:intersection" into a GroupOverlapFunc, see OVERLAP_SPEC_USAGE
+*/
+func ParseOverlapSpec(spec string) (GroupOverlapFunc, error) {
+	aggregation, pair, _ := strings.Cut(spec, ":")
+	overlapFunc, exists := pairOverlapFuncs[pair]
+	if !exists {
+		return nil, fmt.Errorf("invalid overlap spec %q, expected %v", spec, OVERLAP_SPEC_USAGE)
+	}
+	switch aggregation {
+	case "any":
+		return AnyPair(overlapFunc), nil
+	case "all":
+		return AllPairs(overlapFunc), nil
+	}
+	return nil, fmt.Errorf("invalid overlap spec %q, expected %v", spec, OVERLAP_SPEC_USAGE)
+}
+
+func OverlapFuncNames() []string {
+	names := []string{}
+	for name := range overlapFuncs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/*
+ElfAssignment is the inclusive range of sections an elf is cleaning
+*/
 type ElfAssignment struct {
-	sections string
+	low  int
+	high int
 }
 
 func (ea ElfAssignment) GetRange() (int, int) {
-	sectionRange := strings.Split(ea.sections, "-")
-	low, _ := strconv.Atoi(sectionRange[0])
-	high, _ := strconv.Atoi(sectionRange[1])
-	return low, high
+	return ea.low, ea.high
 }
 
 func (ea ElfAssignment) IsSubsetOfPairedElf(other ElfAssignment) bool {
@@ -70,4 +292,4 @@ func (ea ElfAssignment) Intersects(other ElfAssignment) bool {
 	thisLow, thisHigh := ea.GetRange()
 	otherLow, otherHigh := other.GetRange()
 	return (thisLow <= otherLow && thisHigh >= otherLow) || (thisHigh <= otherHigh && thisHigh >= otherLow)
-}
\ No newline at end of file
+}
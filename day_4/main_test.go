@@ -0,0 +1,131 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGroupOverlapFuncs(t *testing.T) {
+	tests := []struct {
+		elfGroup          string
+		wantAnySubset     bool
+		wantAllSubset     bool
+		wantAnyIntersect  bool
+		wantAllIntersect  bool
+		wantCommonSection bool
+		wantContainsAll   bool
+	}{
+		// A single elf has no pairs, so any pair is false while all pairs, a common section and containing all others are true
+		{"2-4", false, true, false, true, true, true},
+		// Examples from the puzzle
+		{"2-4,6-8", false, false, false, false, false, false},
+		{"5-7,7-9", false, false, true, true, true, false},
+		{"2-8,3-7", true, true, true, true, true, true},
+		{"6-6,4-6", true, true, true, true, true, true},
+		{"2-6,4-8", false, false, true, true, true, false},
+		// Every pair intersects w/o a section that all 3 elves share
+		{"1-3,3-5,2-4", false, false, true, true, true, false},
+		{"1-2,2-3,3-4", false, false, true, false, false, false},
+		{"1-4,4-7,3-5", false, false, true, true, true, false},
+		{"1-5,3-7,5-9", false, false, true, true, true, false},
+		{"1-5,3-7,1-2", true, false, true, false, false, false},
+		{"1-9,2-3,5-8", true, false, true, false, false, true},
+		{"1-9,2-3,4-5,9-9", true, false, true, false, false, true},
+		{"3-3,3-3,3-3", true, true, true, true, true, true},
+	}
+	for _, tt := range tests {
+		assignments, err := ParseElfAssignments(tt.elfGroup)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, check := range []struct {
+			name        string
+			overlapFunc GroupOverlapFunc
+			want        bool
+		}{
+			{"AnyPair(EvaluateSubset)", AnyPair(EvaluateSubset), tt.wantAnySubset},
+			{"AllPairs(EvaluateSubset)", AllPairs(EvaluateSubset), tt.wantAllSubset},
+			{"AnyPair(EvaluateIntersection)", AnyPair(EvaluateIntersection), tt.wantAnyIntersect},
+			{"AllPairs(EvaluateIntersection)", AllPairs(EvaluateIntersection), tt.wantAllIntersect},
+			{"EvaluateCommonSection", EvaluateCommonSection, tt.wantCommonSection},
+			{"EvaluateContainsAll", EvaluateContainsAll, tt.wantContainsAll},
+		} {
+			if got := check.overlapFunc(assignments); got != check.want {
+				t.Errorf("%v(%v) = %v, want %v", check.name, tt.elfGroup, got, check.want)
+			}
+		}
+	}
+}
+
+func TestParseElfGroups(t *testing.T) {
+	tests := []struct {
+		name       string
+		lines      []string
+		wantGroups int
+		wantErr    string
+	}{
+		{"empty lines are skipped", []string{"2-4,6-8", "", "1-1,1-2,3-3", ""}, 2, ""},
+		{"missing dash", []string{"2-4,6-8", "2-4,68"}, 0, `line 2: invalid section range "68"`},
+		{"not a number", []string{"a-4,6-8"}, 0, `line 1: invalid section range "a-4"`},
+		{"missing range", []string{"2-4,"}, 0, `line 1: invalid section range ""`},
+		{"backwards range", []string{"1-2", "1-2", "4-3"}, 0, `line 3: invalid section range "4-3", the range ends before it starts`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elfGroups, err := parseElfGroups(tt.lines)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(elfGroups) != tt.wantGroups {
+				t.Errorf("parsed %v elf groups, want %v", len(elfGroups), tt.wantGroups)
+			}
+		})
+	}
+}
+
+func TestLookupOverlapFunc(t *testing.T) {
+	if err := RegisterOverlapFunc("test-loose", AnyPair(EvaluateIntersection)); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterOverlapFunc("test-loose", AnyPair(EvaluateSubset)); err == nil {
+		t.Error("registering test-loose twice succeeded, want an error")
+	}
+
+	assignments, err := ParseElfAssignments("1-3,3-5,2-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		want    bool
+		wantErr string
+	}{
+		{"test-loose", true, ""},
+		{"any-subset", false, ""},
+		{"all:intersection", true, ""},
+		{"any:subset", false, ""},
+		{"some:subset", false, "invalid overlap spec"},
+		{"all:union", false, "invalid overlap spec"},
+		{"no-such-func", false, "unknown overlap func"},
+	}
+	for _, tt := range tests {
+		overlapFunc, err := LookupOverlapFunc(tt.name)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LookupOverlapFunc(%q) error = %v, want it to contain %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("LookupOverlapFunc(%q): %v", tt.name, err)
+		} else if got := overlapFunc(assignments); got != tt.want {
+			t.Errorf("LookupOverlapFunc(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
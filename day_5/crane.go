@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	UNLIMITED_CAPACITY = 0
+)
+
+/*
+Crane is a model of crane that moves crates between stacks and keeps track of the work it has done
+*/
+type Crane interface {
+	Name() string
+	// Moves crates according to instructions, assumes instructions are valid for the current state of the crate stack
+	MoveCrates(CrateStack, MoveInstructions)
+	Stats() CraneStats
+}
+
+/*
+CraneStats counts the operations performed by a crane
+Moves is the # of instructions handled, Lifts is the # of times crates were picked up and CratesCarried is the total # of
+crates carried across all lifts
+*/
+type CraneStats struct {
+	Moves         int
+	Lifts         int
+	CratesCarried int
+}
+
+/*
+CapacityCrane lifts at most capacity crates at a time, preserving the order of the crates within each lift
+The CrateMover9000 is a CapacityCrane w/ a capacity of 1 and the CrateMover9001 has UNLIMITED_CAPACITY
+*/
+type CapacityCrane struct {
+	name     string
+	capacity int
+	stats    CraneStats
+}
+
+func NewCrateMover9000() *CapacityCrane {
+	return &CapacityCrane{"CrateMover9000", 1, CraneStats{}}
+}
+
+func NewCrateMover9001() *CapacityCrane {
+	return &CapacityCrane{"CrateMover9001", UNLIMITED_CAPACITY, CraneStats{}}
+}
+
+func NewCapacityCrane(capacity int) *CapacityCrane {
+	return &CapacityCrane{fmt.Sprintf("capacity-%v", capacity), capacity, CraneStats{}}
+}
+
+func (cc *CapacityCrane) Name() string {
+	return cc.name
+}
+
+func (cc *CapacityCrane) MoveCrates(crateStack CrateStack, instructions MoveInstructions) {
+	cc.stats.Moves++
+	lifts := liftCrates(crateStack, instructions, cc.capacity)
+	cc.stats.Lifts += lifts
+	if lifts > 0 {
+		cc.stats.CratesCarried += instructions.Quantity
+	}
+}
+
+func (cc *CapacityCrane) Stats() CraneStats {
+	return cc.stats
+}
+
+/*
+AdjacentCrane can only move crates between neighbouring stacks (in ascending stack id order)
+Crates headed for a stack further away are shuttled one stack at a time, each lift of the wrapped crane is carried all the way to
+its destination before the next one is picked up so that crates end up in the same order as w/ the wrapped crane on its own
+*/
+type AdjacentCrane struct {
+	crane *CapacityCrane
+	stats CraneStats
+}
+
+func NewAdjacentCrane(crane *CapacityCrane) Crane {
+	return &AdjacentCrane{crane, CraneStats{}}
+}
+
+func (ac *AdjacentCrane) Name() string {
+	return fmt.Sprintf("adjacent-%v", ac.crane.Name())
+}
+
+func (ac *AdjacentCrane) MoveCrates(crateStack CrateStack, instructions MoveInstructions) {
+	ac.stats.Moves++
+	ids := crateStack.StackIds()
+	from := sort.SearchInts(ids, instructions.From)
+	to := sort.SearchInts(ids, instructions.To)
+	step := 1
+	if to < from {
+		step = -1
+	}
+	for remaining := instructions.Quantity; remaining > 0 && from != to; {
+		liftSize := nextLiftSize(remaining, ac.crane.capacity)
+		for i := from; i != to; i += step {
+			liftCrates(crateStack, MoveInstructions{liftSize, ids[i], ids[i+step]}, UNLIMITED_CAPACITY)
+			ac.stats.Lifts++
+			ac.stats.CratesCarried += liftSize
+		}
+		remaining -= liftSize
+	}
+}
+
+/*
+Lifts and crates carried include every hop between neighbouring stacks
+*/
+func (ac *AdjacentCrane) Stats() CraneStats {
+	return ac.stats
+}
+
+/*
+craneFactory builds a crane that can be selected by name from the CLI, capacity is only used by capacity-limited cranes
+*/
+type craneFactory struct {
+	new          func(capacity int) Crane
+	usesCapacity bool
+}
+
+var craneFactories = map[string]craneFactory{
+	"9000":              {func(int) Crane { return NewCrateMover9000() }, false},
+	"9001":              {func(int) Crane { return NewCrateMover9001() }, false},
+	"capacity":          {func(capacity int) Crane { return NewCapacityCrane(capacity) }, true},
+	"adjacent-9000":     {func(int) Crane { return NewAdjacentCrane(NewCrateMover9000()) }, false},
+	"adjacent-9001":     {func(int) Crane { return NewAdjacentCrane(NewCrateMover9001()) }, false},
+	"adjacent-capacity": {func(capacity int) Crane { return NewAdjacentCrane(NewCapacityCrane(capacity)) }, true},
+}
+
+func NewCrane(name string, capacity int) (Crane, error) {
+	factory, exists := craneFactories[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown crane %q, expected one of: %v", name, strings.Join(CraneNames(), ", "))
+	}
+	if factory.usesCapacity && capacity < 1 {
+		return nil, fmt.Errorf("crane capacity must be at least 1, got %v", capacity)
+	}
+	return factory.new(capacity), nil
+}
+
+func CraneNames() []string {
+	names := []string{}
+	for name := range craneFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAdjacentCraneMatchesDirectCrane(t *testing.T) {
+	moves := []MoveInstructions{{3, 1, 3}, {2, 3, 4}, {3, 4, 1}, {1, 2, 2}, {3, 1, 2}}
+	for _, name := range []string{"9000", "9001", "capacity"} {
+		direct, err := NewCrane(name, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		adjacent, err := NewCrane("adjacent-"+name, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		directStack := CrateStack{1: {"A", "B", "C"}, 2: {"X"}, 3: {}, 4: {"Y"}}
+		adjacentStack := CrateStack{1: {"A", "B", "C"}, 2: {"X"}, 3: {}, 4: {"Y"}}
+		for _, move := range moves {
+			direct.MoveCrates(directStack, move)
+			adjacent.MoveCrates(adjacentStack, move)
+			if !reflect.DeepEqual(adjacentStack, directStack) {
+				t.Fatalf("%v after %+v: crates are %v, want %v", adjacent.Name(), move, adjacentStack, directStack)
+			}
+		}
+	}
+}
+
+func TestAdjacentCraneStats(t *testing.T) {
+	crane, err := NewCrane("adjacent-9000", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crateStack := CrateStack{1: {"A", "B", "C"}, 2: {}, 3: {}}
+	crane.MoveCrates(crateStack, MoveInstructions{3, 1, 3})
+	if want := (CrateStack{1: {}, 2: {}, 3: {"C", "B", "A"}}); !reflect.DeepEqual(crateStack, want) {
+		t.Errorf("crates are %v, want %v", crateStack, want)
+	}
+	// Each of the 3 crates is lifted once per hop
+	if got, want := crane.Stats(), (CraneStats{1, 6, 6}); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestNewCraneCapacity(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		wantErr  string
+	}{
+		{"9000", 0, ""},
+		{"9001", -1, ""},
+		{"adjacent-9001", 0, ""},
+		{"capacity", 2, ""},
+		{"capacity", 0, "crane capacity must be at least 1"},
+		{"adjacent-capacity", 0, "crane capacity must be at least 1"},
+		{"9002", 1, "unknown crane"},
+	}
+	for _, tt := range tests {
+		_, err := NewCrane(tt.name, tt.capacity)
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("NewCrane(%q, %v): %v", tt.name, tt.capacity, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("NewCrane(%q, %v) error = %v, want it to contain %q", tt.name, tt.capacity, err, tt.wantErr)
+		}
+	}
+}
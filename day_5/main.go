@@ -1,10 +1,15 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 /*
@@ -12,132 +17,455 @@ Parses input file for initial crate diagram and instructions - then moves crates
 model of CrateMover900x that is being used.
 */
 func main() {
-	part1Crates := moveCratesAndGetTopCrates(CrateMover9000Func)
-	log.Printf("Part 1: These are the %v top crates after the move: %v", len(part1Crates), strings.Join(part1Crates, ""))
-	part2Crates := moveCratesAndGetTopCrates(CrateMover9001Func)
-	log.Printf("Part 2: These are the %v top crates after the move: %v", len(part2Crates), strings.Join(part2Crates, ""))
+	lenient := flag.Bool("lenient", false, "move whatever crates are available for impossible instructions instead of failing")
+	craneNames := flag.String("crane", "", fmt.Sprintf("comma-separated list of cranes to move crates with, one of: %v", strings.Join(CraneNames(), ", ")))
+	capacity := flag.Int("capacity", 3, "max # of crates per lift for capacity-limited cranes")
+	trace := flag.Bool("trace", false, "print the crate diagram after every move")
+	framesDir := flag.String("frames", "", "directory to write one text frame per move to, grouped by crane")
+	replay := flag.Bool("replay", false, "replay every move as a terminal animation once the crane is done")
+	delay := flag.Duration("delay", 100*time.Millisecond, "time between frames of the terminal replay")
+	planTarget := flag.String("plan", "", "path to a target crate diagram, prints instructions that turn the initial diagram into it")
+	planModel := flag.String("plan-model", "9001", fmt.Sprintf("model of CrateMover900x to plan for, one of: %v", strings.Join(PlanModelNames(), ", ")))
+	flag.Parse()
 
+	if *planTarget != "" {
+		if err := printPlan(*planTarget, *planModel); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	mode := STRICT
+	if *lenient {
+		mode = LENIENT
+	}
+
+	labels := []string{"Part 1", "Part 2"}
+	cranes := []Crane{NewCrateMover9000(), NewCrateMover9001()}
+	if *craneNames != "" {
+		labels, cranes = []string{}, []Crane{}
+		for _, name := range strings.Split(*craneNames, ",") {
+			crane, err := NewCrane(name, *capacity)
+			if err != nil {
+				log.Fatal(err)
+			}
+			labels = append(labels, crane.Name())
+			cranes = append(cranes, crane)
+		}
+	}
+
+	for i, crane := range cranes {
+		observers := []MoveObserver{}
+		if *trace {
+			observers = append(observers, TraceObserver(os.Stdout))
+		}
+		var recorder *FrameRecorder
+		if *framesDir != "" || *replay {
+			recorder = &FrameRecorder{}
+			observers = append(observers, recorder.Record)
+		}
+
+		topCrates, warnings, err := moveCratesAndGetTopCrates(crane, mode, observers...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		logWarnings(warnings)
+
+		if recorder != nil && *replay {
+			if err := recorder.Replay(os.Stdout, *delay); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if recorder != nil && *framesDir != "" {
+			if err := recorder.WriteFrames(filepath.Join(*framesDir, crane.Name())); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		log.Printf("%v: These are the %v top crates after the move: %v", labels[i], len(topCrates), strings.Join(topCrates, ""))
+		if *craneNames != "" {
+			stats := crane.Stats()
+			log.Printf("%v: Performed %v moves using %v lifts, carrying %v crates", labels[i], stats.Moves, stats.Lifts, stats.CratesCarried)
+		}
+	}
 }
 
 /*
-Moves crates based on the provided inputfile and particular model of CrateMover900x
+Moves crates based on the provided inputfile and particular model of crane
+In STRICT mode every instruction is validated before any crates are moved, in LENIENT mode malformed lines are skipped and
+impossible instructions are adjusted or skipped, a warning is returned for each of them
+Observers are notified of the initial state and after every move
 */
-func moveCratesAndGetTopCrates(crateMoverFunc CrateMoverFunction) []string {
-	crateStack, moveInstructions := parseInputFile()
-	for _, instructions := range moveInstructions {
-		// Take the top N crates from the first stack, reverse them
-		fromStack := crateMoverFunc(crateStack, instructions)
+func moveCratesAndGetTopCrates(crane Crane, mode MoveMode, observers ...MoveObserver) ([]string, []string, error) {
+	crateStack, moveInstructions, warnings, err := parseInputFile(mode)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		// append crates from first stack to the second stack
-		toStack := crateStack.CopyStack(instructions.To)
-		newStack := append(fromStack, toStack...)
-		crateStack[instructions.To] = newStack
+	if mode == STRICT {
+		if errs := ValidateMoveInstructions(crateStack, moveInstructions); len(errs) > 0 {
+			return nil, nil, fmt.Errorf("found %v invalid move instructions, first: %w", len(errs), errs[0])
+		}
+	}
 
-		// Pop the first N crates off the first stack and reassign
-		crateStack[instructions.From] = crateStack[instructions.From][instructions.Quantity:]
+	for _, observer := range observers {
+		observer(0, nil, crateStack)
 	}
 
-	numStacks := len(crateStack)
-	topCrates := []string{}
-	for i := 1; i <= numStacks; i++ {
-		topCrates = append(topCrates, string(crateStack[i][0]))
+	for i, instructions := range moveInstructions {
+		if mode == LENIENT {
+			adjusted, err := crateStack.AdjustMoveInstructions(instructions)
+			if err != nil {
+				warnings = append(warnings, InstructionError{i, instructions, err.Error()}.Error())
+				if adjusted.Quantity == 0 {
+					continue
+				}
+			}
+			instructions = adjusted
+		}
+		crane.MoveCrates(crateStack, instructions)
+		for _, observer := range observers {
+			observer(i+1, &instructions, crateStack)
+		}
+	}
+	return crateStack.TopCrates(), warnings, nil
+}
+
+/*
+Prints the initial diagram from the input file along w/ instructions that turn it into the diagram at targetPath
+*/
+func printPlan(targetPath string, model string) error {
+	planModel, exists := planModels[model]
+	if !exists {
+		return fmt.Errorf("unknown model %q, expected one of: %v", model, strings.Join(PlanModelNames(), ", "))
+	}
+	// Move instructions are irrelevant to the plan so malformed ones are ignored
+	initial, _, _, err := parseInputFile(LENIENT)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(targetPath)
+	if err != nil {
+		return err
+	}
+	target, _, err := parseInitialCrateDiagram(strings.Split(string(data), "\n"))
+	if err != nil {
+		return fmt.Errorf("%v: %w", targetPath, err)
+	}
+
+	plan, err := PlanMoveInstructions(initial, target, planModel)
+	if err != nil {
+		return err
+	}
+	fmt.Print(FormatPlan(initial, plan))
+	return nil
+}
+
+func logWarnings(warnings []string) {
+	for _, warning := range warnings {
+		log.Printf("Warning: %v", warning)
 	}
-	return topCrates
 }
 
 /*
 Parses the provided input file for the initial state of the crate stack and the move instructions to apply on the crate stack
+Malformed move instructions are handled according to mode, see parseMoveInstructions
 */
-func parseInputFile() (CrateStack, []MoveInstructions) {
-	data, _ := os.ReadFile("resources/input")
+func parseInputFile(mode MoveMode) (CrateStack, []MoveInstructions, []string, error) {
+	data, err := os.ReadFile("resources/input")
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	lines := strings.Split(string(data), "\n")
-	crateStack, moveLinesStart := parseInitialCrateDiagram(lines)
-	moveInstructions := parseMoveInstructions(lines[moveLinesStart:])
-	return crateStack, moveInstructions
+	crateStack, moveLinesStart, err := parseInitialCrateDiagram(lines)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	moveInstructions, warnings, err := parseMoveInstructions(lines[moveLinesStart:], moveLinesStart, mode)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return crateStack, moveInstructions, warnings, nil
 }
 
 /*
 Parses the initial state of the crate stack from the given input file
+The column-number footer is located first and used to assign each '[X]' crate on the lines above it to a column, this allows
+ragged lines, multi-character crate ids and any number of stacks
 Returns the initialized CrateStack and line # where move set starts in input file
 */
-func parseInitialCrateDiagram(lines []string) (CrateStack, int) {
-	var initialCrateStack CrateStack = CrateStack{}
-	var moveLinesStart int
+func parseInitialCrateDiagram(lines []string) (CrateStack, int, error) {
+	footerIdx := -1
 	for i, line := range lines {
-		for j := 0; j <= len(line); j += 4 {
-			crate := line[j : j+3]
-			crateId := rune(crate[1])
-
-			/*
-			 This is a hack to stop processing when we get to line w/ only column numbers
-			*/
-			_, err := strconv.Atoi(string(crateId))
-			if err == nil {
-				// Skip the current line and the following blank line to get to start of move instructions
-				moveLinesStart = i + 2
-				break
-			}
+		if isColumnFooter(line) {
+			footerIdx = i
+			break
+		}
+	}
+	if footerIdx == -1 {
+		return nil, 0, fmt.Errorf("crate diagram has no column-number footer")
+	}
+
+	columns, err := parseColumnFooter(lines[footerIdx], footerIdx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	initialCrateStack := CrateStack{}
+	for _, column := range columns {
+		initialCrateStack[column.id] = []string{}
+	}
+
+	for i := 0; i < footerIdx; i++ {
+		line := lines[i]
+		for j := 0; j < len(line); j++ {
+			switch line[j] {
+			case ' ':
+				continue
+			case '[':
+				end := strings.IndexByte(line[j:], ']')
+				if end == -1 {
+					return nil, 0, fmt.Errorf("line %v, column %v: unterminated crate", i+1, j+1)
+				}
+				end += j
+				crateId := line[j+1 : end]
+				if crateId == "" || strings.ContainsAny(crateId, " []") {
+					return nil, 0, fmt.Errorf("line %v, column %v: invalid crate id %q", i+1, j+1, crateId)
+				}
 
-			// Add the crateId rune to the relevant column if it is non-empty
-			if crateId != ' ' {
-				columnId := (j / 4) + 1
-				columnCrates, _ := initialCrateStack[columnId]
-				initialCrateStack[columnId] = append(columnCrates, crateId)
+				columnId, found := columns.columnAt(j, end)
+				if !found {
+					return nil, 0, fmt.Errorf("line %v, column %v: crate %q is not above any column number", i+1, j+1, crateId)
+				}
+				initialCrateStack[columnId] = append(initialCrateStack[columnId], crateId)
+				j = end
+			default:
+				return nil, 0, fmt.Errorf("line %v, column %v: unexpected character %q in crate diagram", i+1, j+1, line[j])
 			}
 		}
-		if moveLinesStart != 0 {
-			break
+	}
+
+	// Crates were read top-to-bottom, flip each stack so the top crate is last
+	for _, stack := range initialCrateStack {
+		for i, j := 0, len(stack)-1; i < j; i, j = i+1, j-1 {
+			stack[i], stack[j] = stack[j], stack[i]
+		}
+	}
+
+	// Skip the footer and the following blank line to get to start of move instructions
+	moveLinesStart := footerIdx + 1
+	if moveLinesStart < len(lines) && strings.TrimSpace(lines[moveLinesStart]) == "" {
+		moveLinesStart++
+	}
+	return initialCrateStack, moveLinesStart, nil
+}
+
+/*
+The footer is the first line made up solely of column numbers
+*/
+func isColumnFooter(line string) bool {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false
+	}
+	for _, field := range fields {
+		if _, err := strconv.Atoi(field); err != nil {
+			return false
 		}
 	}
+	return true
+}
+
+/*
+diagramColumn is a column number from the footer along with the span of characters it occupies
+*/
+type diagramColumn struct {
+	id    int
+	start int
+	end   int
+}
 
-	return initialCrateStack, moveLinesStart
+type diagramColumns []diagramColumn
+
+func parseColumnFooter(line string, lineIdx int) (diagramColumns, error) {
+	columns := diagramColumns{}
+	seen := map[int]struct{}{}
+	for j := 0; j < len(line); j++ {
+		if line[j] == ' ' {
+			continue
+		}
+		start := j
+		for j < len(line) && line[j] != ' ' {
+			j++
+		}
+		id, err := strconv.Atoi(line[start:j])
+		if err != nil {
+			return nil, fmt.Errorf("line %v, column %v: invalid column number %q", lineIdx+1, start+1, line[start:j])
+		}
+		if _, exists := seen[id]; exists {
+			return nil, fmt.Errorf("line %v, column %v: duplicate column number %v", lineIdx+1, start+1, id)
+		}
+		seen[id] = struct{}{}
+		columns = append(columns, diagramColumn{id, start, j - 1})
+	}
+	return columns, nil
+}
+
+/*
+Returns the id of the column whose number overlaps the characters between start and end (inclusive)
+*/
+func (columns diagramColumns) columnAt(start, end int) (int, bool) {
+	// Columns are ordered by position, find the first one that doesn't end before start
+	i := sort.Search(len(columns), func(i int) bool { return columns[i].end >= start })
+	if i < len(columns) && columns[i].start <= end {
+		return columns[i].id, true
+	}
+	return 0, false
 }
 
 /*
 Parses the set of instructions listed below the crate diagram, used to determine how many crates move from one stack to another
+firstLineIdx is the index of the first of the lines in the input file, so that errors point at the right line
+In STRICT mode the first malformed line is returned as an error, in LENIENT mode malformed lines are skipped and a warning is
+returned for each of them
 */
-func parseMoveInstructions(lines []string) []MoveInstructions {
+func parseMoveInstructions(lines []string, firstLineIdx int, mode MoveMode) ([]MoveInstructions, []string, error) {
 	var instructions []MoveInstructions = []MoveInstructions{}
-	for _, line := range lines {
-		splitLine := strings.Split(line, " ")
-		quantity, _ := strconv.Atoi(splitLine[1])
-		from, _ := strconv.Atoi(splitLine[3])
-		to, _ := strconv.Atoi(splitLine[5])
-		instructions = append(instructions, MoveInstructions{quantity, from, to})
+	warnings := []string{}
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		instruction, err := parseMoveInstruction(line, firstLineIdx+i)
+		if err != nil {
+			if mode == STRICT {
+				return nil, nil, err
+			}
+			warnings = append(warnings, fmt.Sprintf("skipping %v", err))
+			continue
+		}
+		instructions = append(instructions, instruction)
+	}
+	return instructions, warnings, nil
+}
+
+/*
+Parses a single "move N from A to B" line
+*/
+func parseMoveInstruction(line string, lineIdx int) (MoveInstructions, error) {
+	splitLine := strings.Split(line, " ")
+	if len(splitLine) != 6 || splitLine[0] != "move" || splitLine[2] != "from" || splitLine[4] != "to" {
+		return MoveInstructions{}, fmt.Errorf("line %v: expected \"move N from A to B\", got %q", lineIdx+1, line)
+	}
+
+	names := [3]string{"quantity", "source stack", "destination stack"}
+	values := [3]int{}
+	column := 1
+	for k, field := range splitLine {
+		if k%2 == 1 {
+			value, err := strconv.Atoi(field)
+			if err != nil {
+				return MoveInstructions{}, fmt.Errorf("line %v, column %v: invalid %v %q", lineIdx+1, column, names[k/2], field)
+			}
+			values[k/2] = value
+		}
+		column += len(field) + 1
 	}
-	return instructions
+	return MoveInstructions{values[0], values[1], values[2]}, nil
 }
 
 /*
 CrateMoverFunction describes the way in which a given model of 'CrateMover900x' moves crates given some CrateStack and MoveInstructions
+Implementations move crates in place and only touch the top N crates of each stack so that a move is O(N)
+*/
+type CrateMoverFunction func(CrateStack, MoveInstructions)
+
+func CrateMover9000Func(crateStack CrateStack, instructions MoveInstructions) {
+	// Lift the top N crates one at a time, which reverses their order on the destination stack
+	liftCrates(crateStack, instructions, 1)
+}
+
+func CrateMover9001Func(crateStack CrateStack, instructions MoveInstructions) {
+	// Lift the top N crates at once, preserving their order
+	liftCrates(crateStack, instructions, UNLIMITED_CAPACITY)
+}
+
+/*
+Moves crates in lifts of at most capacity crates, each lift preserves the order of the crates it carries
+Assumes instructions are valid for the current state of the crate stack, returns the number of lifts performed
 */
-type CrateMoverFunction func(CrateStack, MoveInstructions) []rune
+func liftCrates(crateStack CrateStack, instructions MoveInstructions, capacity int) int {
+	// Moving crates onto the stack they were lifted from leaves the stack unchanged
+	if instructions.From == instructions.To {
+		return 0
+	}
 
-func CrateMover9000Func(crateStack CrateStack, instructions MoveInstructions) []rune {
-	// Take the top N crates from the first stack, reverse them
-	fromStack := crateStack.CopyStack(instructions.From)[:instructions.Quantity]
-	for i, j := 0, len(fromStack)-1; i < j; i, j = i+1, j-1 {
-		fromStack[i], fromStack[j] = fromStack[j], fromStack[i]
+	fromStack := crateStack[instructions.From]
+	toStack := crateStack[instructions.To]
+	var lifts int
+	for remaining := instructions.Quantity; remaining > 0; lifts++ {
+		liftSize := nextLiftSize(remaining, capacity)
+		top := len(fromStack) - liftSize
+		toStack = append(toStack, fromStack[top:]...)
+		fromStack = fromStack[:top]
+		remaining -= liftSize
 	}
-	return fromStack
+	crateStack[instructions.To] = toStack
+	crateStack[instructions.From] = fromStack
+	return lifts
 }
 
-func CrateMover9001Func(crateStack CrateStack, instructions MoveInstructions) []rune {
-	return crateStack.CopyStack(instructions.From)[:instructions.Quantity]
+/*
+Returns the # of crates in the next lift of a crane w/ the given capacity when remaining crates are left to move
+*/
+func nextLiftSize(remaining int, capacity int) int {
+	if capacity != UNLIMITED_CAPACITY && remaining > capacity {
+		return capacity
+	}
+	return remaining
 }
 
 /*
-CrateStack is a mapping of column ids to crate stacks labeled by some character
+CrateStack is a mapping of column ids to crate stacks labeled by some string
+Each stack is ordered bottom-to-top, IE the top crate is the last element
 */
-type CrateStack map[int][]rune
+type CrateStack map[int][]string
 
-func (cs CrateStack) CopyStack(id int) []rune {
+func (cs CrateStack) CopyStack(id int) []string {
 	originalArr := cs[id]
-	newArr := make([]rune, len(originalArr))
+	newArr := make([]string, len(originalArr))
 	copy(newArr, originalArr)
 	return newArr
 }
 
+/*
+Returns the top crate of each stack in ascending stack id order, empty stacks are reported as EMPTY_STACK
+*/
+func (cs CrateStack) TopCrates() []string {
+	topCrates := []string{}
+	for _, id := range cs.StackIds() {
+		stack := cs[id]
+		if len(stack) == 0 {
+			topCrates = append(topCrates, EMPTY_STACK)
+		} else {
+			topCrates = append(topCrates, stack[len(stack)-1])
+		}
+	}
+	return topCrates
+}
+
+/*
+Returns the ids of all stacks in ascending order
+*/
+func (cs CrateStack) StackIds() []int {
+	ids := []int{}
+	for id := range cs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
 /*
 MoveInstructions represents how many crates should be move, where they should be moved from, and where they should be moved to
 */
@@ -146,3 +474,10 @@ type MoveInstructions struct {
 	From     int
 	To       int
 }
+
+/*
+Formats instructions the same way they are written in the input file
+*/
+func (mi MoveInstructions) String() string {
+	return fmt.Sprintf("move %v from %v to %v", mi.Quantity, mi.From, mi.To)
+}
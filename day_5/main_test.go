@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMoveInstructions(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    MoveInstructions
+		wantErr string
+	}{
+		{"valid", "move 3 from 1 to 2", MoveInstructions{3, 1, 2}, ""},
+		{"multi-digit", "move 12 from 10 to 2", MoveInstructions{12, 10, 2}, ""},
+		{"missing destination", "move 3 from 1", MoveInstructions{}, `line 11: expected "move N from A to B", got "move 3 from 1"`},
+		{"wrong keyword", "move 3 to 1 from 2", MoveInstructions{}, "line 11: expected"},
+		{"non-numeric quantity", "move x from 1 to 2", MoveInstructions{}, `line 11, column 6: invalid quantity "x"`},
+		{"non-numeric source", "move 3 from a to 2", MoveInstructions{}, `line 11, column 13: invalid source stack "a"`},
+		{"non-numeric destination", "move 3 from 1 to b", MoveInstructions{}, `line 11, column 18: invalid destination stack "b"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The move instructions start on the 10th line of the input file
+			instructions, warnings, err := parseMoveInstructions([]string{"", tt.line}, 9, STRICT)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+
+				instructions, warnings, err = parseMoveInstructions([]string{"", tt.line}, 9, LENIENT)
+				if err != nil || len(instructions) != 0 || len(warnings) != 1 {
+					t.Errorf("LENIENT: got %v instructions, warnings %v and error %v, want the line to be skipped w/ a warning", len(instructions), warnings, err)
+				}
+				return
+			}
+			if err != nil || len(warnings) != 0 {
+				t.Fatalf("unexpected error %v or warnings %v", err, warnings)
+			}
+			if len(instructions) != 1 || instructions[0] != tt.want {
+				t.Errorf("parsed %v, want [%v]", instructions, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// Minimal plans are only searched for when there are at most this many crates and stacks, larger plans are constructed
+	MAX_SEARCH_CRATES = 8
+	MAX_SEARCH_STACKS = 5
+	// Upper bound on the # of states expanded when searching for a minimal plan before falling back to a constructive plan
+	MAX_SEARCH_EXPANSIONS = 20000
+)
+
+/*
+PlanModel is a model of CrateMover900x that plans can be made for
+*/
+type PlanModel struct {
+	CrateMoverFunc CrateMoverFunction
+	// Whether the crates moved by a single instruction keep their order
+	KeepsOrder bool
+}
+
+/*
+Registry of the CrateMover900x models that plans can be made for
+*/
+var planModels = map[string]PlanModel{
+	"9000": {CrateMover9000Func, false},
+	"9001": {CrateMover9001Func, true},
+}
+
+/*
+Synthesizes move instructions that turn the initial crate stack into the target crate stack w/ the given model of CrateMover900x
+A breadth-first search returns a minimal plan for small stacks, larger stacks or searches that exceed MAX_SEARCH_EXPANSIONS are
+planned one target crate at a time instead, which needs at least 3 stacks but is not guaranteed to be minimal
+*/
+func PlanMoveInstructions(initial, target CrateStack, model PlanModel) ([]MoveInstructions, error) {
+	if err := checkPlanTarget(initial, target); err != nil {
+		return nil, err
+	}
+
+	var numCrates int
+	for _, stack := range initial {
+		numCrates += len(stack)
+	}
+	var plan []MoveInstructions
+	err := errSearchBudget
+	if len(initial) < 3 || (numCrates <= MAX_SEARCH_CRATES && len(initial) <= MAX_SEARCH_STACKS) {
+		plan, err = searchMinimalPlan(initial, target, model.KeepsOrder)
+	}
+	if err != nil {
+		if len(initial) < 3 {
+			return nil, fmt.Errorf("%w, plans for fewer than 3 stacks must be found by search", err)
+		}
+		plan = constructPlan(initial, target, model.CrateMoverFunc)
+	}
+
+	// Replay the plan to make sure it actually produces the target w/ the chosen model
+	result := copyCrateStack(initial)
+	for _, instructions := range plan {
+		if instructions.From != instructions.To {
+			model.CrateMoverFunc(result, instructions)
+		}
+	}
+	if !equalCrateStacks(result, target) {
+		return nil, fmt.Errorf("plan of %v moves does not produce the target diagram", len(plan))
+	}
+	return plan, nil
+}
+
+/*
+The target must use the same stacks and contain exactly the same crates as the initial crate stack
+*/
+func checkPlanTarget(initial, target CrateStack) error {
+	if fmt.Sprint(initial.StackIds()) != fmt.Sprint(target.StackIds()) {
+		return fmt.Errorf("target stacks %v do not match initial stacks %v", target.StackIds(), initial.StackIds())
+	}
+	crateCounts := map[string]int{}
+	for _, stack := range initial {
+		for _, crate := range stack {
+			crateCounts[crate]++
+		}
+	}
+	for _, stack := range target {
+		for _, crate := range stack {
+			crateCounts[crate]--
+		}
+	}
+	for crate, count := range crateCounts {
+		if count > 0 {
+			return fmt.Errorf("crate %q is missing from the target diagram", crate)
+		}
+		if count < 0 {
+			return fmt.Errorf("crate %q is not in the initial diagram", crate)
+		}
+	}
+	return nil
+}
+
+var errSearchBudget = fmt.Errorf("no plan found within %v expanded states", MAX_SEARCH_EXPANSIONS)
+
+/*
+Breadth-first search over every possible move, returns errSearchBudget if the target isn't reached within MAX_SEARCH_EXPANSIONS
+States are encoded as one byte per crate w/ stacks in the order of their ids separated by STACK_SEPARATOR, so moves are applied to
+the encoding directly and the encoding doubles as the key of the set of seen states
+*/
+func searchMinimalPlan(initial, target CrateStack, keepsOrder bool) ([]MoveInstructions, error) {
+	type searchNode struct {
+		state  string
+		parent int
+		move   MoveInstructions
+	}
+
+	ids := initial.StackIds()
+	crateCodes := map[string]byte{}
+	for _, id := range ids {
+		for _, crate := range initial[id] {
+			if _, exists := crateCodes[crate]; !exists {
+				if len(crateCodes) == STACK_SEPARATOR {
+					return nil, fmt.Errorf("cannot search for plans w/ more than %v distinct crates", STACK_SEPARATOR)
+				}
+				crateCodes[crate] = byte(len(crateCodes))
+			}
+		}
+	}
+	targetState := encodeState(target, ids, crateCodes)
+
+	nodes := []searchNode{{encodeState(initial, ids, crateCodes), -1, MoveInstructions{}}}
+	seen := map[string]struct{}{nodes[0].state: {}}
+	stacks := make([][]byte, len(ids))
+	next := []byte{}
+	i := 0
+	for ; i < len(nodes) && i < MAX_SEARCH_EXPANSIONS; i++ {
+		if nodes[i].state == targetState {
+			plan := []MoveInstructions{}
+			for j := i; nodes[j].parent != -1; j = nodes[j].parent {
+				plan = append(plan, nodes[j].move)
+			}
+			for l, r := 0, len(plan)-1; l < r; l, r = l+1, r-1 {
+				plan[l], plan[r] = plan[r], plan[l]
+			}
+			return plan, nil
+		}
+
+		state := []byte(nodes[i].state)
+		for k := range stacks {
+			end := 0
+			for end < len(state) && state[end] != STACK_SEPARATOR {
+				end++
+			}
+			stacks[k] = state[:end]
+			if end < len(state) {
+				state = state[end+1:]
+			}
+		}
+
+		for from := range stacks {
+			for to := range stacks {
+				if from == to {
+					continue
+				}
+				for quantity := 1; quantity <= len(stacks[from]); quantity++ {
+					next = appendMove(next[:0], stacks, from, to, quantity, keepsOrder)
+					if _, exists := seen[string(next)]; exists {
+						continue
+					}
+					key := string(next)
+					seen[key] = struct{}{}
+					nodes = append(nodes, searchNode{key, i, MoveInstructions{quantity, ids[from], ids[to]}})
+				}
+			}
+		}
+	}
+	if i == len(nodes) {
+		return nil, fmt.Errorf("the target diagram cannot be reached, every one of the %v reachable states was searched", len(nodes))
+	}
+	return nil, errSearchBudget
+}
+
+// Separates stacks in the encoding of a search state, no crate is encoded as this byte
+const STACK_SEPARATOR = 0xff
+
+func encodeState(crateStack CrateStack, ids []int, crateCodes map[string]byte) string {
+	state := []byte{}
+	for k, id := range ids {
+		if k > 0 {
+			state = append(state, STACK_SEPARATOR)
+		}
+		for _, crate := range crateStack[id] {
+			state = append(state, crateCodes[crate])
+		}
+	}
+	return string(state)
+}
+
+/*
+Appends the encoding of the state after moving quantity crates between the stacks at the given indices
+*/
+func appendMove(state []byte, stacks [][]byte, from, to, quantity int, keepsOrder bool) []byte {
+	moved := stacks[from][len(stacks[from])-quantity:]
+	for k, stack := range stacks {
+		if k > 0 {
+			state = append(state, STACK_SEPARATOR)
+		}
+		switch k {
+		case from:
+			state = append(state, stack[:len(stack)-quantity]...)
+		case to:
+			state = append(state, stack...)
+			if keepsOrder {
+				state = append(state, moved...)
+			} else {
+				for c := len(moved) - 1; c >= 0; c-- {
+					state = append(state, moved[c])
+				}
+			}
+		default:
+			state = append(state, stack...)
+		}
+	}
+	return state
+}
+
+/*
+Builds every target stack bottom-up, one crate at a time
+Crates that are already in their final position form a fixed base of each stack, which is never disturbed since every other
+crate is only ever moved onto the top of a stack. To place the next crate, whatever sits on top of the base of its target stack
+and on top of the crate itself is cleared onto a third stack and the crate is moved over alone, which works for every model of
+CrateMover900x since the order of cleared crates doesn't matter
+*/
+func constructPlan(initial, target CrateStack, crateMoverFunc CrateMoverFunction) []MoveInstructions {
+	crateStack := copyCrateStack(initial)
+	ids := initial.StackIds()
+	fixed := map[int]int{}
+	plan := []MoveInstructions{}
+	move := func(quantity, from, to int) {
+		instructions := MoveInstructions{quantity, from, to}
+		crateMoverFunc(crateStack, instructions)
+		plan = append(plan, instructions)
+	}
+	otherStack := func(excluded ...int) int {
+		for _, id := range ids {
+			if !containsInt(excluded, id) {
+				return id
+			}
+		}
+		return ids[0]
+	}
+
+	// Fill the target level by level so crates that are already in place at the bottom are fixed as early as possible
+	var maxHeight int
+	for _, stack := range target {
+		if len(stack) > maxHeight {
+			maxHeight = len(stack)
+		}
+	}
+	for level := 0; level < maxHeight; level++ {
+		for _, to := range ids {
+			if level >= len(target[to]) {
+				continue
+			}
+			crate := target[to][level]
+			from, position := locateCrate(crateStack, fixed, crate)
+
+			// The crate is already in its final position
+			if from == to && position == fixed[to] && position == level {
+				fixed[to]++
+				continue
+			}
+
+			// The crate is in the unfixed part of its target stack, move it elsewhere first
+			if from == to {
+				buffer := otherStack(to)
+				if above := len(crateStack[from]) - position - 1; above > 0 {
+					move(above, from, buffer)
+				}
+				move(1, from, buffer)
+				from, position = buffer, len(crateStack[buffer])-1
+			}
+
+			if unfixed := len(crateStack[to]) - fixed[to]; unfixed > 0 {
+				move(unfixed, to, otherStack(to, from))
+			}
+			if above := len(crateStack[from]) - position - 1; above > 0 {
+				move(above, from, otherStack(to, from))
+			}
+			move(1, from, to)
+			fixed[to]++
+		}
+	}
+	return plan
+}
+
+/*
+Finds an unfixed occurrence of crate, preferring the one w/ the fewest crates on top of it
+*/
+func locateCrate(crateStack CrateStack, fixed map[int]int, crate string) (int, int) {
+	bestStack, bestPosition, bestAbove := 0, -1, -1
+	for _, id := range crateStack.StackIds() {
+		stack := crateStack[id]
+		for position := len(stack) - 1; position >= fixed[id]; position-- {
+			if stack[position] != crate {
+				continue
+			}
+			if above := len(stack) - position - 1; bestAbove == -1 || above < bestAbove {
+				bestStack, bestPosition, bestAbove = id, position, above
+			}
+			break
+		}
+	}
+	return bestStack, bestPosition
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func copyCrateStack(crateStack CrateStack) CrateStack {
+	result := CrateStack{}
+	for id := range crateStack {
+		result[id] = crateStack.CopyStack(id)
+	}
+	return result
+}
+
+func equalCrateStacks(a, b CrateStack) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for id, stack := range a {
+		other, exists := b[id]
+		if !exists || len(stack) != len(other) {
+			return false
+		}
+		for i := range stack {
+			if stack[i] != other[i] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func PlanModelNames() []string {
+	names := []string{}
+	for name := range planModels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+/*
+Formats the initial crate diagram followed by the plan, in the same format as the input file
+*/
+func FormatPlan(initial CrateStack, plan []MoveInstructions) string {
+	var sb strings.Builder
+	sb.WriteString(initial.Render())
+	sb.WriteString("\n")
+	for _, instructions := range plan {
+		sb.WriteString(instructions.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
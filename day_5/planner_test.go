@@ -0,0 +1,123 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+/*
+Generates numStacks stacks holding numCrates crates labelled A, B, ... in random positions
+*/
+func randomCrateStack(numStacks, numCrates int, rng *rand.Rand) CrateStack {
+	crateStack := CrateStack{}
+	for id := 1; id <= numStacks; id++ {
+		crateStack[id] = []string{}
+	}
+	for _, c := range rng.Perm(numCrates) {
+		id := 1 + rng.Intn(numStacks)
+		crateStack[id] = append(crateStack[id], string(rune('A'+c)))
+	}
+	return crateStack
+}
+
+/*
+Applies random moves to a copy of the crate stack, so that the result can always be reached w/ the model
+*/
+func shuffleCrateStack(crateStack CrateStack, model PlanModel, rng *rand.Rand) CrateStack {
+	result := copyCrateStack(crateStack)
+	ids := result.StackIds()
+	for n := 0; n < 50; n++ {
+		from, to := ids[rng.Intn(len(ids))], ids[rng.Intn(len(ids))]
+		if from == to || len(result[from]) == 0 {
+			continue
+		}
+		model.CrateMoverFunc(result, MoveInstructions{1 + rng.Intn(len(result[from])), from, to})
+	}
+	return result
+}
+
+func TestPlanRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		numStacks int
+		numCrates int
+	}{
+		{"two stacks", 2, 4},
+		{"searched", 3, 6},
+		{"largest searched", MAX_SEARCH_STACKS, MAX_SEARCH_CRATES},
+		{"constructed", 9, 20},
+	}
+	rng := rand.New(rand.NewSource(1))
+	for _, tt := range tests {
+		for _, modelName := range PlanModelNames() {
+			model := planModels[modelName]
+			t.Run(tt.name+"/"+modelName, func(t *testing.T) {
+				initial := randomCrateStack(tt.numStacks, tt.numCrates, rng)
+				target := shuffleCrateStack(initial, model, rng)
+				plan, err := PlanMoveInstructions(initial, target, model)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// Parse the plan back the same way as the input file and replay it
+				lines := strings.Split(FormatPlan(initial, plan), "\n")
+				parsed, moveLinesStart, err := parseInitialCrateDiagram(lines)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !equalCrateStacks(parsed, initial) {
+					t.Fatalf("parsed initial diagram\n%vwant\n%v", parsed.Render(), initial.Render())
+				}
+				instructions, _, err := parseMoveInstructions(lines[moveLinesStart:], moveLinesStart, STRICT)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(instructions) != len(plan) {
+					t.Fatalf("parsed %v instructions, want %v", len(instructions), len(plan))
+				}
+				if errs := ValidateMoveInstructions(parsed, instructions); len(errs) > 0 {
+					t.Fatalf("plan has invalid instructions: %v", errs)
+				}
+				for _, mi := range instructions {
+					model.CrateMoverFunc(parsed, mi)
+				}
+				if !equalCrateStacks(parsed, target) {
+					t.Errorf("replayed plan produced\n%vwant\n%v", parsed.Render(), target.Render())
+				}
+			})
+		}
+	}
+}
+
+func TestPlanUnreachableTarget(t *testing.T) {
+	// w/ only 2 stacks the CrateMover 9000 can never swap the bottom crates
+	initial := CrateStack{1: {"A", "B"}, 2: {}}
+	target := CrateStack{1: {"B", "A"}, 2: {}}
+	if _, err := PlanMoveInstructions(initial, target, planModels["9000"]); err == nil || !strings.Contains(err.Error(), "cannot be reached") {
+		t.Errorf("error = %v, want the target to be unreachable", err)
+	}
+}
+
+func TestPlanIsMinimal(t *testing.T) {
+	initial := CrateStack{1: {"A", "B", "C"}, 2: {}, 3: {}}
+	tests := []struct {
+		model string
+		want  int
+	}{
+		// Moving all three crates at once keeps their order
+		{"9001", 1},
+		// One crate at a time reverses them, so the order has to be reversed twice
+		{"9000", 2},
+	}
+	for _, tt := range tests {
+		target := CrateStack{1: {}, 2: {"A", "B", "C"}, 3: {}}
+		plan, err := PlanMoveInstructions(initial, target, planModels[tt.model])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(plan) != tt.want {
+			t.Errorf("model %v: plan has %v moves, want %v", tt.model, len(plan), tt.want)
+		}
+	}
+}
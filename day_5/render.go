@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	CLEAR_SCREEN = "\033[H\033[2J"
+)
+
+/*
+MoveObserver is notified w/ the state of the crate stack after the instructions at the given (1-based) step have been applied
+Step 0 is the initial state, in which case instructions is nil
+*/
+type MoveObserver func(step int, instructions *MoveInstructions, crateStack CrateStack)
+
+/*
+Renders the crate stack in the same '[A] [B]' diagram format used by the input file, including the column-number footer
+Every column is as wide as the widest crate label or column number so multi-character crates stay aligned
+*/
+func (cs CrateStack) Render() string {
+	ids := cs.StackIds()
+	width := 3
+	var maxHeight int
+	for _, id := range ids {
+		if idWidth := len(strconv.Itoa(id)); idWidth > width {
+			width = idWidth
+		}
+		for _, crate := range cs[id] {
+			if crateWidth := len(crate) + 2; crateWidth > width {
+				width = crateWidth
+			}
+		}
+		if len(cs[id]) > maxHeight {
+			maxHeight = len(cs[id])
+		}
+	}
+
+	var sb strings.Builder
+	for level := maxHeight - 1; level >= 0; level-- {
+		cells := []string{}
+		for _, id := range ids {
+			cell := ""
+			if level < len(cs[id]) {
+				cell = fmt.Sprintf("[%v]", cs[id][level])
+			}
+			cells = append(cells, centerCell(cell, width))
+		}
+		sb.WriteString(strings.Join(cells, " "))
+		sb.WriteString("\n")
+	}
+
+	footer := []string{}
+	for _, id := range ids {
+		footer = append(footer, centerCell(strconv.Itoa(id), width))
+	}
+	sb.WriteString(strings.Join(footer, " "))
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func centerCell(cell string, width int) string {
+	left := (width - len(cell)) / 2
+	right := width - len(cell) - left
+	return strings.Repeat(" ", left) + cell + strings.Repeat(" ", right)
+}
+
+/*
+Renders a single frame made up of a header describing the step followed by the crate diagram
+*/
+func RenderFrame(step int, instructions *MoveInstructions, crateStack CrateStack) string {
+	header := "initial"
+	if instructions != nil {
+		header = fmt.Sprintf("step %v: %v", step, instructions)
+	}
+	return fmt.Sprintf("%v\n%v", header, crateStack.Render())
+}
+
+/*
+Returns a MoveObserver that writes every frame to w as soon as it happens
+*/
+func TraceObserver(w io.Writer) MoveObserver {
+	return func(step int, instructions *MoveInstructions, crateStack CrateStack) {
+		fmt.Fprintln(w, RenderFrame(step, instructions, crateStack))
+	}
+}
+
+/*
+FrameRecorder keeps every rendered frame so the full move sequence can be exported once the crane is done
+*/
+type FrameRecorder struct {
+	frames []string
+}
+
+func (fr *FrameRecorder) Record(step int, instructions *MoveInstructions, crateStack CrateStack) {
+	fr.frames = append(fr.frames, RenderFrame(step, instructions, crateStack))
+}
+
+func (fr *FrameRecorder) Frames() []string {
+	return fr.frames
+}
+
+/*
+Writes each frame to its own numbered text file in dir, creating dir if needed
+*/
+func (fr *FrameRecorder) WriteFrames(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for i, frame := range fr.frames {
+		path := filepath.Join(dir, fmt.Sprintf("frame_%06d.txt", i))
+		if err := os.WriteFile(path, []byte(frame), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+Replays the recorded frames as a terminal animation, clearing the screen between frames
+*/
+func (fr *FrameRecorder) Replay(w io.Writer, delay time.Duration) error {
+	for _, frame := range fr.frames {
+		if _, err := fmt.Fprint(w, CLEAR_SCREEN, frame); err != nil {
+			return err
+		}
+		time.Sleep(delay)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+MoveMode determines how impossible move instructions are handled
+*/
+type MoveMode int
+
+const (
+	// Reject the whole set of instructions if any of them is impossible
+	STRICT MoveMode = iota
+	// Move whatever crates are available and skip instructions that reference unknown stacks
+	LENIENT
+)
+
+const (
+	EMPTY_STACK = "_"
+)
+
+/*
+InstructionError describes why the instruction at a given (0-based) position can't be applied
+*/
+type InstructionError struct {
+	Index        int
+	Instructions MoveInstructions
+	Reason       string
+}
+
+func (ie InstructionError) Error() string {
+	return fmt.Sprintf("instruction %v (%v): %v", ie.Index+1, ie.Instructions, ie.Reason)
+}
+
+/*
+Simulates the height of every stack over the full set of instructions and returns an InstructionError for each instruction
+that is impossible given the stack heights at that point
+Invalid instructions are not applied to the simulated heights
+*/
+func ValidateMoveInstructions(crateStack CrateStack, instructions []MoveInstructions) []error {
+	heights := map[int]int{}
+	for id, stack := range crateStack {
+		heights[id] = len(stack)
+	}
+	heightOf := func(id int) (int, bool) {
+		height, exists := heights[id]
+		return height, exists
+	}
+
+	errs := []error{}
+	for i, mi := range instructions {
+		if reason := checkMove(heightOf, mi); reason != "" {
+			errs = append(errs, InstructionError{i, mi, reason})
+			continue
+		}
+		heights[mi.From] -= mi.Quantity
+		heights[mi.To] += mi.Quantity
+	}
+	return errs
+}
+
+/*
+Returns the reason instructions are impossible given the current stack heights or an empty string if they are valid
+*/
+func checkMove(heightOf func(int) (int, bool), instructions MoveInstructions) string {
+	fromHeight, fromExists := heightOf(instructions.From)
+	if !fromExists {
+		return fmt.Sprintf("unknown stack %v", instructions.From)
+	}
+	if _, toExists := heightOf(instructions.To); !toExists {
+		return fmt.Sprintf("unknown stack %v", instructions.To)
+	}
+	if instructions.Quantity < 0 {
+		return fmt.Sprintf("negative quantity %v", instructions.Quantity)
+	}
+	if instructions.Quantity > fromHeight {
+		return fmt.Sprintf("cannot move %v crates from stack %v holding %v", instructions.Quantity, instructions.From, fromHeight)
+	}
+	return ""
+}
+
+/*
+Returns the height of the given stack and whether it exists
+*/
+func (cs CrateStack) height(id int) (int, bool) {
+	stack, exists := cs[id]
+	return len(stack), exists
+}
+
+/*
+Adjusts instructions so that they can be applied to the current crate stack
+The quantity is capped at the number of crates available, instructions that reference unknown stacks or a negative quantity
+are adjusted to move nothing - an error describing the adjustment is returned whenever instructions had to change
+*/
+func (cs CrateStack) AdjustMoveInstructions(instructions MoveInstructions) (MoveInstructions, error) {
+	reason := checkMove(cs.height, instructions)
+	if reason == "" {
+		return instructions, nil
+	}
+
+	adjusted := MoveInstructions{0, instructions.From, instructions.To}
+	fromHeight, fromExists := cs.height(instructions.From)
+	_, toExists := cs.height(instructions.To)
+	if fromExists && toExists && instructions.Quantity > 0 {
+		adjusted.Quantity = fromHeight
+		reason = fmt.Sprintf("%v, moving %v instead", reason, adjusted.Quantity)
+	}
+	return adjusted, errors.New(reason)
+}
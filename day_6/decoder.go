@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+/*
+EventType identifies what a Decoder found in the signal
+*/
+type EventType int
+
+const (
+	START_OF_PACKET EventType = iota
+	START_OF_MESSAGE
+	MESSAGE
+)
+
+func (et EventType) String() string {
+	switch et {
+	case START_OF_PACKET:
+		return "start-of-packet"
+	case START_OF_MESSAGE:
+		return "start-of-message"
+	case MESSAGE:
+		return "message"
+	}
+	return fmt.Sprintf("EventType(%d)", int(et))
+}
+
+/*
+Event is emitted by a Decoder
+Offset is the # of characters processed before the first character following the marker, for a MESSAGE it is the offset of the
+first character of Message
+*/
+type Event struct {
+	Type    EventType
+	Offset  int
+	Message []byte
+}
+
+/*
+Decoder consumes a signal one character at a time so that unbounded signals can be processed as they arrive
+The start-of-packet marker is reported once, after which every start-of-message marker is reported and the characters between two
+start-of-message markers (or between the last marker and the end of the signal) are framed as a MESSAGE. Message markers never
+overlap, the window is emptied after each one so the next marker is made up of characters from the following message
+*/
+type Decoder struct {
+	reader         *bufio.Reader
+	packetMatcher  MarkerMatcher
+	messageMatcher MarkerMatcher
+	messageSize    int
+	offset         int
+	packetFound    bool
+	// message holds the characters after the last start-of-message marker, nil until the first one is found
+	message     []byte
+	messageFrom int
+	pending     []Event
+	done        bool
+}
+
+/*
+Returns a Decoder for the default protocol, where markers are 4 (packet) and 14 (message) distinct characters
+*/
+func NewDecoder(r io.Reader) *Decoder {
+	return NewDecoderWithSpecs(r, DistinctSpec(4), DistinctSpec(14))
+}
+
+func NewDecoderWithSpecs(r io.Reader, packetSpec, messageSpec MarkerSpec) *Decoder {
+	return &Decoder{
+		reader:         bufio.NewReader(r),
+		packetMatcher:  packetSpec.NewMatcher(),
+		messageMatcher: messageSpec.NewMatcher(),
+		messageSize:    messageSpec.Size(),
+	}
+}
+
+/*
+Returns the next event in the signal, blocking until enough characters have been read to emit it
+Returns io.EOF once the signal has ended and every event has been returned
+*/
+func (d *Decoder) Next() (Event, error) {
+	for len(d.pending) == 0 {
+		if d.done {
+			return Event{}, io.EOF
+		}
+		c, err := d.reader.ReadByte()
+		if err == io.EOF {
+			d.done = true
+			d.flushMessage(len(d.message))
+			continue
+		}
+		if err != nil {
+			return Event{}, err
+		}
+		d.process(c)
+	}
+
+	event := d.pending[0]
+	d.pending = d.pending[1:]
+	return event, nil
+}
+
+func (d *Decoder) process(c byte) {
+	d.offset++
+	if d.message != nil {
+		d.message = append(d.message, c)
+	}
+
+	if !d.packetFound && d.packetMatcher.Push(c) {
+		d.packetFound = true
+		d.pending = append(d.pending, Event{START_OF_PACKET, d.offset, nil})
+	}
+
+	if d.messageMatcher.Push(c) {
+		d.messageMatcher.Reset()
+		// The marker itself was appended to the previous message, leave it out of the frame
+		d.flushMessage(len(d.message) - d.messageSize)
+		d.pending = append(d.pending, Event{START_OF_MESSAGE, d.offset, nil})
+		d.message = []byte{}
+		d.messageFrom = d.offset
+	}
+}
+
+/*
+Emits the first length characters of the current message as a MESSAGE event
+*/
+func (d *Decoder) flushMessage(length int) {
+	if d.message == nil {
+		return
+	}
+	d.pending = append(d.pending, Event{MESSAGE, d.messageFrom, d.message[:length]})
+	d.message = nil
+}
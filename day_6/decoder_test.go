@@ -0,0 +1,54 @@
+package main
+
+import (
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestDecoderOverPipe(t *testing.T) {
+	// Packet markers are 2 distinct characters and message markers 4, every message is made of repeated characters so that no marker
+	// is found inside of it
+	const signal = "aab" + "bbbb" + "wwxyz" + "hhgg" + "mmnop" + "qq"
+	want := []Event{
+		{START_OF_PACKET, 3, nil},
+		{START_OF_MESSAGE, 12, nil},
+		{MESSAGE, 12, []byte("hhggm")},
+		{START_OF_MESSAGE, 21, nil},
+		// The last message is flushed once the signal ends
+		{MESSAGE, 21, []byte("qq")},
+	}
+
+	for _, chunkSize := range []int{1, 3, 5, len(signal)} {
+		r, w := io.Pipe()
+		go func(chunkSize int) {
+			for start := 0; start < len(signal); start += chunkSize {
+				end := start + chunkSize
+				if end > len(signal) {
+					end = len(signal)
+				}
+				if _, err := w.Write([]byte(signal[start:end])); err != nil {
+					w.CloseWithError(err)
+					return
+				}
+			}
+			w.Close()
+		}(chunkSize)
+
+		decoder := NewDecoderWithSpecs(r, DistinctSpec(2), DistinctSpec(4))
+		got := []Event{}
+		for {
+			event, err := decoder.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("chunks of %v: %v", chunkSize, err)
+			}
+			got = append(got, event)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("chunks of %v: got events %v, want %v", chunkSize, got, want)
+		}
+	}
+}
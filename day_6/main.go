@@ -1,6 +1,8 @@
 package main
 
 import (
+	"flag"
+	"io"
 	"log"
 	"os"
 )
@@ -9,40 +11,108 @@ import (
 Part 1: Processes signal to identify start-of-packet marker (sequence of 4 distinct characters)
 */
 func main() {
+	all := flag.Bool("all", false, "report the position of every marker in the signal instead of only the first")
+	stream := flag.Bool("stream", false, "decode a signal streamed over stdin, reporting markers and messages as they arrive")
+	packetMarker := flag.String("packet-marker", "distinct:4", "spec of the start-of-packet marker, "+MARKER_SPEC_USAGE)
+	messageMarker := flag.String("message-marker", "distinct:14", "spec of the start-of-message marker, "+MARKER_SPEC_USAGE)
+	flag.Parse()
+
+	packetSpec, err := ParseMarkerSpec(*packetMarker)
+	if err != nil {
+		log.Fatal(err)
+	}
+	messageSpec, err := ParseMarkerSpec(*messageMarker)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *stream {
+		if err := decodeStream(NewDecoderWithSpecs(os.Stdin, packetSpec, messageSpec)); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	inputSignal := parseInputFile()
-	startOfPacketMarker := identifyStartOfMarker(inputSignal, 4)
+	if *all {
+		log.Printf("Part 1: For given signal, start-of-packet markers were detected after %v characters", identifyAllMarkers(inputSignal, packetSpec))
+		log.Printf("Part 2: For given signal, start-of-message markers were detected after %v characters", identifyAllMarkers(inputSignal, messageSpec))
+		return
+	}
+	startOfPacketMarker := identifyStartOfMarker(inputSignal, packetSpec)
 	log.Printf("Part 1: For given signal, start-of-packet marker was detected after %v characters", startOfPacketMarker)
-	startOfMessageMarker := identifyStartOfMarker(inputSignal, 14)
+	startOfMessageMarker := identifyStartOfMarker(inputSignal, messageSpec)
 	log.Printf("Part 2: For given signal, start-of-message marker was detected after %v characters", startOfMessageMarker)
 }
 
 /*
-Function to detect a marker based on N distinct/sequential characters in a signal
+Function to detect a marker (EX N distinct/sequential characters) in a signal
+Returns the # of characters processed once the first marker is complete or 0 if the signal has no marker
 */
-func identifyStartOfMarker(signal string, distChars int) int {
-	var startOfPacketMarker int
-	empty := struct{}{}
-	for i := 0; i <= len(signal)-distChars; i++ {
-		if startOfPacketMarker == 0 {
-			characterSet := make(map[rune]struct{}, distChars)
-			characterGroup := signal[i : i+distChars]
-			for j := 0; j < len(characterGroup); j++ {
-				c := rune(characterGroup[j])
-				characterSet[c] = empty
-			}
-			if len(characterSet) == distChars {
-				// Add distChars to get the index of the last character in the start-of-marker
-				startOfPacketMarker = i + distChars
+func identifyStartOfMarker(signal string, spec MarkerSpec) int {
+	markers := scanMarkers(signal, spec, true)
+	if len(markers) == 0 {
+		return 0
+	}
+	return markers[0]
+}
+
+/*
+Returns the position after every marker in a signal, in ascending order
+*/
+func identifyAllMarkers(signal string, spec MarkerSpec) []int {
+	return scanMarkers(signal, spec, false)
+}
+
+/*
+Slides a window the size of the marker over the signal, checking for a marker after every character
+*/
+func scanMarkers(signal string, spec MarkerSpec, firstOnly bool) []int {
+	markers := []int{}
+	if spec.Size() > len(signal) {
+		return markers
+	}
+
+	matcher := spec.NewMatcher()
+	for i := 0; i < len(signal); i++ {
+		if matcher.Push(signal[i]) {
+			// Add 1 to get the # of characters processed up to and including the last character in the marker
+			markers = append(markers, i+1)
+			if firstOnly {
+				break
 			}
 		}
 	}
-	return startOfPacketMarker
+	return markers
+}
+
+/*
+Logs every event found by the decoder until the signal ends
+*/
+func decodeStream(decoder *Decoder) error {
+	for {
+		event, err := decoder.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if event.Type == MESSAGE {
+			log.Printf("%v at offset %v: %q", event.Type, event.Offset, event.Message)
+		} else {
+			log.Printf("%v marker detected after %v characters", event.Type, event.Offset)
+		}
+	}
 }
 
 /*
 Parses the user-specific input file provided by https://adventofcode.com/2022/day/6/input
 */
 func parseInputFile() string {
-	data, _ := os.ReadFile("resources/input")
+	data, err := os.ReadFile("resources/input")
+	if err != nil {
+		panic(err)
+	}
 	return string(data)
 }
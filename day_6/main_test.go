@@ -0,0 +1,99 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIdentifyStartOfMarker(t *testing.T) {
+	tests := []struct {
+		signal      string
+		wantPacket  int
+		wantMessage int
+	}{
+		// Examples from the puzzle
+		{"mjqjpqmgbljsphdztnvjfqwrcgsmlb", 7, 19},
+		{"nppdvjthqldpwncqszvftbrmjlhg", 6, 23},
+		{"nznrnfrfntjfmvfwmzdfjlvtqnbhcprsg", 10, 29},
+		{"zcfzfwzzqfrljwzlrfnpqdbhtmscgvjw", 11, 26},
+		{"abc", 0, 0},
+		{"aaaaaaaaaaaaaaaaaaaa", 0, 0},
+	}
+	for _, tt := range tests {
+		if got := identifyStartOfMarker(tt.signal, DistinctSpec(4)); got != tt.wantPacket {
+			t.Errorf("identifyStartOfMarker(%q, distinct:4) = %v, want %v", tt.signal, got, tt.wantPacket)
+		}
+		if got := identifyStartOfMarker(tt.signal, DistinctSpec(14)); got != tt.wantMessage {
+			t.Errorf("identifyStartOfMarker(%q, distinct:14) = %v, want %v", tt.signal, got, tt.wantMessage)
+		}
+	}
+}
+
+func TestIdentifyAllMarkers(t *testing.T) {
+	// Every byte value twice in a row, so every window of 256 characters is distinct and every larger window repeats a character
+	allBytesTwice := &strings.Builder{}
+	for n := 0; n < 2; n++ {
+		for c := 0; c < ALPHABET_SIZE; c++ {
+			allBytesTwice.WriteByte(byte(c))
+		}
+	}
+	markersFrom := func(first int) []int {
+		markers := []int{}
+		for i := first; i <= allBytesTwice.Len(); i++ {
+			markers = append(markers, i)
+		}
+		return markers
+	}
+
+	tests := []struct {
+		signal string
+		spec   MarkerSpec
+		want   []int
+	}{
+		{"abcabc", DistinctSpec(3), []int{3, 4, 5, 6}},
+		{"aabcdd", DistinctSpec(3), []int{4, 5}},
+		{"aabbaab", RepeatsSpec{4, 2}, []int{4, 5, 6, 7}},
+		{"aaabaaa", RepeatsSpec{3, 2}, []int{4, 5, 6}},
+		{"ab", DistinctSpec(3), []int{}},
+		{allBytesTwice.String(), DistinctSpec(ALPHABET_SIZE), markersFrom(ALPHABET_SIZE)},
+		{allBytesTwice.String(), DistinctSpec(ALPHABET_SIZE + 1), []int{}},
+		{allBytesTwice.String(), RepeatsSpec{300, 2}, markersFrom(300)},
+	}
+	for _, tt := range tests {
+		if got := identifyAllMarkers(tt.signal, tt.spec); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("identifyAllMarkers(%.20q, %v) = %v, want %v", tt.signal, tt.spec, got, tt.want)
+		}
+	}
+}
+
+/*
+Compares the counting matcher against recounting every window on random signals
+*/
+func TestRepeatsMatchesWindowScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 500; n++ {
+		signal := make([]byte, rng.Intn(40))
+		for i := range signal {
+			signal[i] = byte('a' + rng.Intn(1+rng.Intn(6)))
+		}
+		spec := RepeatsSpec{1 + rng.Intn(8), 1 + rng.Intn(3)}
+
+		want := []int{}
+		for end := spec.WindowSize; end <= len(signal); end++ {
+			counts := map[byte]int{}
+			matches := true
+			for _, c := range signal[end-spec.WindowSize : end] {
+				counts[c]++
+				matches = matches && counts[c] <= spec.MaxRepeats
+			}
+			if matches {
+				want = append(want, end)
+			}
+		}
+		if got := identifyAllMarkers(string(signal), spec); !reflect.DeepEqual(got, want) {
+			t.Fatalf("identifyAllMarkers(%q, %v) = %v, want %v", signal, spec, got, want)
+		}
+	}
+}
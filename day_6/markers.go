@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Signals are processed byte by byte
+	ALPHABET_SIZE     = 256
+	MARKER_SPEC_USAGE = "one of distinct:N, repeats:N:K, pattern:N:REGEX or checksum:N:MOD:REM"
+)
+
+/*
+MarkerMatcher is fed a signal one character at a time and reports whether the latest characters form a marker
+*/
+type MarkerMatcher interface {
+	Push(c byte) bool
+	// Empties the matcher so that the next marker can't overlap w/ characters that were already pushed
+	Reset()
+}
+
+/*
+MarkerSpec defines what a marker looks like, every marker is a window of Size() characters
+*/
+type MarkerSpec interface {
+	Size() int
+	NewMatcher() MarkerMatcher
+	String() string
+}
+
+/*
+Parses a marker spec, which is one of:
+  - distinct:N         N distinct characters
+  - repeats:N:K        N characters in which no character appears more than K times
+  - pattern:N:REGEX    N characters that are matched in full by REGEX
+  - checksum:N:MOD:REM N characters whose byte values sum to REM modulo MOD
+*/
+func ParseMarkerSpec(spec string) (MarkerSpec, error) {
+	kind, args, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "distinct":
+		values, err := parseSpecInts(spec, args, 1)
+		if err != nil {
+			return nil, err
+		}
+		return DistinctSpec(values[0]), nil
+	case "repeats":
+		values, err := parseSpecInts(spec, args, 2)
+		if err != nil {
+			return nil, err
+		}
+		if values[1] < 1 {
+			return nil, fmt.Errorf("marker spec %q: max repeats must be at least 1", spec)
+		}
+		return RepeatsSpec{values[0], values[1]}, nil
+	case "pattern":
+		sizeArg, expr, found := strings.Cut(args, ":")
+		if !found {
+			return nil, fmt.Errorf("marker spec %q: expected pattern:N:REGEX", spec)
+		}
+		values, err := parseSpecInts(spec, sizeArg, 1)
+		if err != nil {
+			return nil, err
+		}
+		pattern, err := regexp.Compile(fmt.Sprintf("^(?:%v)$", expr))
+		if err != nil {
+			return nil, fmt.Errorf("marker spec %q: %w", spec, err)
+		}
+		return PatternSpec{values[0], expr, pattern}, nil
+	case "checksum":
+		values, err := parseSpecInts(spec, args, 3)
+		if err != nil {
+			return nil, err
+		}
+		if values[1] < 1 {
+			return nil, fmt.Errorf("marker spec %q: modulus must be at least 1", spec)
+		}
+		if values[2] < 0 || values[2] >= values[1] {
+			return nil, fmt.Errorf("marker spec %q: remainder must be between 0 and %v", spec, values[1]-1)
+		}
+		return ChecksumSpec{values[0], values[1], values[2]}, nil
+	}
+	return nil, fmt.Errorf("unknown marker spec %q, expected %v", spec, MARKER_SPEC_USAGE)
+}
+
+/*
+Parses exactly n colon-separated integers, the first of which is the size of the marker and must be positive
+*/
+func parseSpecInts(spec string, args string, n int) ([]int, error) {
+	fields := strings.Split(args, ":")
+	if len(fields) != n {
+		return nil, fmt.Errorf("marker spec %q: expected %v arguments, got %v", spec, n, len(fields))
+	}
+	values := []int{}
+	for _, field := range fields {
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("marker spec %q: %w", spec, err)
+		}
+		values = append(values, value)
+	}
+	if values[0] < 1 {
+		return nil, fmt.Errorf("marker spec %q: size must be at least 1", spec)
+	}
+	return values, nil
+}
+
+/*
+charRing holds the last N characters of a signal
+*/
+type charRing struct {
+	chars  []byte
+	filled int
+	next   int
+}
+
+func newCharRing(size int) *charRing {
+	return &charRing{chars: make([]byte, size)}
+}
+
+/*
+Adds a character to the ring, returns the character that was dropped to make room for it if the ring was full
+*/
+func (cr *charRing) Push(c byte) (byte, bool) {
+	var dropped byte
+	full := cr.Full()
+	if full {
+		dropped = cr.chars[cr.next]
+	} else {
+		cr.filled++
+	}
+	cr.chars[cr.next] = c
+	cr.next = (cr.next + 1) % len(cr.chars)
+	return dropped, full
+}
+
+func (cr *charRing) Full() bool {
+	return cr.filled == len(cr.chars)
+}
+
+/*
+Returns the characters in the ring from oldest to newest
+*/
+func (cr *charRing) Contents() []byte {
+	if !cr.Full() {
+		return cr.chars[:cr.filled]
+	}
+	return append(append([]byte{}, cr.chars[cr.next:]...), cr.chars[:cr.next]...)
+}
+
+func (cr *charRing) Reset() {
+	cr.filled, cr.next = 0, 0
+}
+
+/*
+RepeatsSpec matches windows of Size characters in which no character appears more than MaxRepeats times
+*/
+type RepeatsSpec struct {
+	WindowSize int
+	MaxRepeats int
+}
+
+/*
+The original marker definition, N distinct/sequential characters
+*/
+func DistinctSpec(size int) RepeatsSpec {
+	return RepeatsSpec{size, 1}
+}
+
+func (rs RepeatsSpec) Size() int {
+	return rs.WindowSize
+}
+
+func (rs RepeatsSpec) String() string {
+	if rs.MaxRepeats == 1 {
+		return fmt.Sprintf("distinct:%v", rs.WindowSize)
+	}
+	return fmt.Sprintf("repeats:%v:%v", rs.WindowSize, rs.MaxRepeats)
+}
+
+func (rs RepeatsSpec) NewMatcher() MarkerMatcher {
+	return &repeatsMatcher{ring: newCharRing(rs.WindowSize), maxRepeats: rs.MaxRepeats}
+}
+
+/*
+repeatsMatcher keeps a count of each character in the window along w/ the # of characters that exceed the max # of repeats,
+so that checking for a marker after every character is O(1)
+A window larger than the alphabet allows never matches
+*/
+type repeatsMatcher struct {
+	ring       *charRing
+	counts     [ALPHABET_SIZE]int
+	maxRepeats int
+	exceeded   int
+}
+
+func (rm *repeatsMatcher) Push(c byte) bool {
+	if dropped, full := rm.ring.Push(c); full {
+		if rm.counts[dropped] == rm.maxRepeats+1 {
+			rm.exceeded--
+		}
+		rm.counts[dropped]--
+	}
+	rm.counts[c]++
+	if rm.counts[c] == rm.maxRepeats+1 {
+		rm.exceeded++
+	}
+	return rm.ring.Full() && rm.exceeded == 0
+}
+
+func (rm *repeatsMatcher) Reset() {
+	rm.ring.Reset()
+	rm.counts = [ALPHABET_SIZE]int{}
+	rm.exceeded = 0
+}
+
+/*
+PatternSpec matches windows of Size characters that are matched in full by a regular expression
+*/
+type PatternSpec struct {
+	WindowSize int
+	Expr       string
+	pattern    *regexp.Regexp
+}
+
+func (ps PatternSpec) Size() int {
+	return ps.WindowSize
+}
+
+func (ps PatternSpec) String() string {
+	return fmt.Sprintf("pattern:%v:%v", ps.WindowSize, ps.Expr)
+}
+
+func (ps PatternSpec) NewMatcher() MarkerMatcher {
+	return &patternMatcher{newCharRing(ps.WindowSize), ps.pattern}
+}
+
+/*
+patternMatcher runs the regular expression over the whole window after every character
+*/
+type patternMatcher struct {
+	ring    *charRing
+	pattern *regexp.Regexp
+}
+
+func (pm *patternMatcher) Push(c byte) bool {
+	pm.ring.Push(c)
+	return pm.ring.Full() && pm.pattern.Match(pm.ring.Contents())
+}
+
+func (pm *patternMatcher) Reset() {
+	pm.ring.Reset()
+}
+
+/*
+ChecksumSpec matches windows of Size characters whose byte values sum to Remainder modulo Modulus
+*/
+type ChecksumSpec struct {
+	WindowSize int
+	Modulus    int
+	Remainder  int
+}
+
+func (cs ChecksumSpec) Size() int {
+	return cs.WindowSize
+}
+
+func (cs ChecksumSpec) String() string {
+	return fmt.Sprintf("checksum:%v:%v:%v", cs.WindowSize, cs.Modulus, cs.Remainder)
+}
+
+func (cs ChecksumSpec) NewMatcher() MarkerMatcher {
+	return &checksumMatcher{ring: newCharRing(cs.WindowSize), spec: cs}
+}
+
+/*
+checksumMatcher keeps a rolling sum of the window so that checking for a marker after every character is O(1)
+*/
+type checksumMatcher struct {
+	ring *charRing
+	spec ChecksumSpec
+	sum  int
+}
+
+func (cm *checksumMatcher) Push(c byte) bool {
+	if dropped, full := cm.ring.Push(c); full {
+		cm.sum -= int(dropped)
+	}
+	cm.sum += int(c)
+	return cm.ring.Full() && cm.sum%cm.spec.Modulus == cm.spec.Remainder
+}
+
+func (cm *checksumMatcher) Reset() {
+	cm.ring.Reset()
+	cm.sum = 0
+}
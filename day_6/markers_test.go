@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMarkerSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr string
+	}{
+		{"distinct:4", "distinct:4", ""},
+		{"repeats:5:2", "repeats:5:2", ""},
+		{"pattern:3:a.c", "pattern:3:a.c", ""},
+		{"checksum:4:7:0", "checksum:4:7:0", ""},
+		{"checksum:4:7:6", "checksum:4:7:6", ""},
+		{"distinct:0", "", "size must be at least 1"},
+		{"repeats:5:0", "", "max repeats must be at least 1"},
+		{"pattern:3", "", "expected pattern:N:REGEX"},
+		{"checksum:4:0:0", "", "modulus must be at least 1"},
+		{"checksum:4:7:7", "", "remainder must be between 0 and 6"},
+		{"checksum:4:7:-1", "", "remainder must be between 0 and 6"},
+		{"checksum:4:7", "", "expected 3 arguments, got 2"},
+		{"unique:4", "", "unknown marker spec"},
+	}
+	for _, tt := range tests {
+		spec, err := ParseMarkerSpec(tt.spec)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseMarkerSpec(%q) error = %v, want it to contain %q", tt.spec, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseMarkerSpec(%q): %v", tt.spec, err)
+		} else if spec.String() != tt.want {
+			t.Errorf("ParseMarkerSpec(%q) = %v, want %v", tt.spec, spec, tt.want)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// Max # of distinct partial sums kept at any point before close sums are merged, which makes the plan approximate
+	MAX_CLEANUP_STATES = 1 << 14
+)
+
+/*
+CleanupMode determines what a CleanupPlan may delete
+*/
+type CleanupMode int
+
+const (
+	DIRECTORIES_ONLY CleanupMode = iota
+	FILES_ONLY
+	DIRECTORIES_AND_FILES
+)
+
+func ParseCleanupMode(mode string) (CleanupMode, error) {
+	switch mode {
+	case "dirs":
+		return DIRECTORIES_ONLY, nil
+	case "files":
+		return FILES_ONLY, nil
+	case "both":
+		return DIRECTORIES_AND_FILES, nil
+	}
+	return DIRECTORIES_ONLY, fmt.Errorf("unknown cleanup mode %q, expected dirs, files or both", mode)
+}
+
+/*
+CleanupPlan is a set of non-nested paths whose deletion frees at least the requested space
+Exact is false if partial sums had to be merged along the way, in which case a plan freeing slightly less space may exist
+*/
+type CleanupPlan struct {
+	Paths []string
+	Freed int
+	Exact bool
+}
+
+/*
+cleanupItem is a file or directory that may be deleted, end is the index of the first item after its subtree
+*/
+type cleanupItem struct {
+	path  string
+	size  int
+	isDir bool
+	end   int
+}
+
+/*
+Flattens the directory into cleanup items in pre-order, so that every subtree is a contiguous range of items
+*/
+func (dir *Directory) cleanupItems(items []cleanupItem) []cleanupItem {
+	idx := len(items)
+	items = append(items, cleanupItem{dir.Path(), dir.CalculateSize(), true, 0})
+	for _, subdir := range dir.SortedSubdirectories() {
+		items = subdir.cleanupItems(items)
+	}
+	for _, e := range dir.sortedEntries(BY_NAME) {
+		if e.dir == nil {
+			items = append(items, cleanupItem{dir.childPath(e.name), e.size, false, len(items) + 1})
+		}
+	}
+	items[idx].end = len(items)
+	return items
+}
+
+/*
+selection is a persistent list of deleted paths, so that states can share their common prefix
+*/
+type selection struct {
+	path string
+	next *selection
+}
+
+func (s *selection) paths() []string {
+	paths := []string{}
+	for ; s != nil; s = s.next {
+		paths = append(paths, s.path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+/*
+cleanupState is a total size freed so far along w/ the paths deleted to free it
+*/
+type cleanupState struct {
+	freed int
+	sel   *selection
+}
+
+/*
+Merges two lists of states sorted by freed space, keeping the first state for any given amount
+*/
+func mergeStates(a, b []cleanupState) []cleanupState {
+	result := make([]cleanupState, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		var next cleanupState
+		if j == len(b) || (i < len(a) && a[i].freed <= b[j].freed) {
+			next, i = a[i], i+1
+		} else {
+			next, j = b[j], j+1
+		}
+		if len(result) == 0 || result[len(result)-1].freed != next.freed {
+			result = append(result, next)
+		}
+	}
+	return result
+}
+
+/*
+Keeps only the smallest state in each of MAX_CLEANUP_STATES evenly sized buckets below needed
+Returns false if any state was dropped
+*/
+func trimStates(states []cleanupState, needed int) ([]cleanupState, bool) {
+	if len(states) <= MAX_CLEANUP_STATES {
+		return states, true
+	}
+	width := needed/MAX_CLEANUP_STATES + 1
+	result := []cleanupState{}
+	for _, state := range states {
+		if len(result) == 0 || result[len(result)-1].freed/width != state.freed/width {
+			result = append(result, state)
+		}
+	}
+	return result, false
+}
+
+/*
+Finds the set of non-nested directories and/or files w/ the smallest total size that frees at least spaceToFree
+Items are visited in pre-order, each one is either skipped (moving on to the next item, IE its contents for a directory) or
+deleted (jumping past its subtree), tracking every amount of space below spaceToFree that can be freed at each item
+*/
+func (dir *Directory) PlanCleanup(spaceToFree int, mode CleanupMode) (CleanupPlan, error) {
+	if spaceToFree <= 0 {
+		return CleanupPlan{[]string{}, 0, true}, nil
+	}
+
+	items := dir.cleanupItems([]cleanupItem{})
+	reach := make([][]cleanupState, len(items)+1)
+	reach[0] = []cleanupState{{0, nil}}
+	best := cleanupState{-1, nil}
+	exact := true
+	for i, item := range items {
+		states := reach[i]
+		reach[i] = nil
+		reach[i+1] = mergeStates(reach[i+1], states)
+
+		if (item.isDir && mode != FILES_ONLY) || (!item.isDir && mode != DIRECTORIES_ONLY) {
+			deleted := []cleanupState{}
+			for _, state := range states {
+				freed := state.freed + item.size
+				sel := &selection{item.path, state.sel}
+				if freed >= spaceToFree {
+					// States are sorted so this is the least space that can be freed by deleting item
+					if best.freed == -1 || freed < best.freed {
+						best = cleanupState{freed, sel}
+					}
+					break
+				}
+				deleted = append(deleted, cleanupState{freed, sel})
+			}
+			reach[item.end] = mergeStates(reach[item.end], deleted)
+		}
+
+		// Nothing can beat freeing exactly the space needed
+		if best.freed == spaceToFree {
+			exact = true
+			break
+		}
+
+		var trimmed bool
+		if reach[i+1], trimmed = trimStates(reach[i+1], spaceToFree); !trimmed {
+			exact = false
+		}
+		if reach[item.end], trimmed = trimStates(reach[item.end], spaceToFree); !trimmed {
+			exact = false
+		}
+	}
+
+	if best.freed == -1 {
+		return CleanupPlan{}, fmt.Errorf("no combination of deletions frees %v", spaceToFree)
+	}
+	return CleanupPlan{best.sel.paths(), best.freed, exact}, nil
+}
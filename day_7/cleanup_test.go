@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlanCleanup(t *testing.T) {
+	root := parseExample(t)
+	tests := []struct {
+		name        string
+		spaceToFree int
+		mode        CleanupMode
+		wantPaths   []string
+		wantFreed   int
+		wantErr     string
+	}{
+		{"puzzle example", 8381165, DIRECTORIES_ONLY, []string{"/d"}, 24933642, ""},
+		{"nothing to free", 0, DIRECTORIES_ONLY, []string{}, 0, ""},
+		{"dirs", 94000, DIRECTORIES_ONLY, []string{"/a"}, 94853, ""},
+		{"files", 94000, FILES_ONLY, []string{"/a/f", "/a/g", "/a/h.lst"}, 94269, ""},
+		{"both", 8600000, DIRECTORIES_AND_FILES, []string{"/d/d.ext", "/d/j"}, 9686326, ""},
+		{"root dir", 30000000, DIRECTORIES_ONLY, []string{"/"}, 48381165, ""},
+		{"exact fit", 584, DIRECTORIES_ONLY, []string{"/a/e"}, 584, ""},
+		{"more than the whole disk", 48381166, DIRECTORIES_AND_FILES, nil, 0, "no combination of deletions frees 48381166"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := root.PlanCleanup(tt.spaceToFree, tt.mode)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(plan, CleanupPlan{tt.wantPaths, tt.wantFreed, true}) {
+				t.Errorf("PlanCleanup(%v) = %+v, want %v freed by deleting %v", tt.spaceToFree, plan, tt.wantFreed, tt.wantPaths)
+			}
+		})
+	}
+}
+
+/*
+Compares PlanCleanup against every combination of deletions on small random trees
+*/
+func TestPlanCleanupMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 300; n++ {
+		root := newDirectory(PATH_SEPARATOR, nil)
+		randomTree(root, 3, rng)
+		for _, mode := range []CleanupMode{DIRECTORIES_ONLY, FILES_ONLY, DIRECTORIES_AND_FILES} {
+			sums := freeableSums(root, mode)
+			spaceToFree := 1 + rng.Intn(root.CalculateSize()+1)
+			want := -1
+			for sum := range sums {
+				if sum >= spaceToFree && (want == -1 || sum < want) {
+					want = sum
+				}
+			}
+
+			plan, err := root.PlanCleanup(spaceToFree, mode)
+			if want == -1 {
+				if err == nil {
+					t.Fatalf("tree %v, mode %v: PlanCleanup(%v) = %+v, want an error", n, mode, spaceToFree, plan)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("tree %v, mode %v: PlanCleanup(%v): %v", n, mode, spaceToFree, err)
+			}
+			if plan.Freed != want || !plan.Exact {
+				t.Fatalf("tree %v, mode %v: PlanCleanup(%v) = %+v, want %v freed exactly", n, mode, spaceToFree, plan, want)
+			}
+			checkPlan(t, root, plan, mode)
+		}
+	}
+}
+
+func TestPlanCleanupApproximate(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	root := newDirectory(PATH_SEPARATOR, nil)
+	for i := 0; i < 40; i++ {
+		if err := root.AddFile(&File{fmt.Sprintf("f%v", i), 1 + rng.Intn(1<<40)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spaceToFree := root.CalculateSize() / 2
+	plan, err := root.PlanCleanup(spaceToFree, FILES_ONLY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Exact {
+		t.Errorf("PlanCleanup(%v).Exact = true, want false once more than %v partial sums are reachable", spaceToFree, MAX_CLEANUP_STATES)
+	}
+	if plan.Freed < spaceToFree {
+		t.Errorf("PlanCleanup(%v).Freed = %v, want at least %v", spaceToFree, plan.Freed, spaceToFree)
+	}
+	checkPlan(t, root, plan, FILES_ONLY)
+}
+
+/*
+Fills dir w/ up to 3 files and, while depth allows, up to 2 random subdirectories
+*/
+func randomTree(dir *Directory, depth int, rng *rand.Rand) {
+	for i := rng.Intn(4); i > 0; i-- {
+		dir.AddFile(&File{fmt.Sprintf("f%v", i), 1 + rng.Intn(100)})
+	}
+	if depth == 0 {
+		return
+	}
+	for i := rng.Intn(3); i > 0; i-- {
+		name := fmt.Sprintf("d%v", i)
+		dir.AddSubdirectory(name)
+		randomTree(dir.subdirectories[name], depth-1, rng)
+	}
+}
+
+/*
+Returns every amount of space that can be freed by deleting non-nested directories and/or files of dir, including dir itself
+*/
+func freeableSums(dir *Directory, mode CleanupMode) map[int]bool {
+	sums := map[int]bool{0: true}
+	combine := func(options map[int]bool) {
+		next := map[int]bool{}
+		for sum := range sums {
+			for option := range options {
+				next[sum+option] = true
+			}
+		}
+		sums = next
+	}
+	for _, subdir := range dir.subdirectories {
+		combine(freeableSums(subdir, mode))
+	}
+	if mode != DIRECTORIES_ONLY {
+		for _, file := range dir.files {
+			combine(map[int]bool{0: true, file.size: true})
+		}
+	}
+	if mode != FILES_ONLY {
+		sums[dir.CalculateSize()] = true
+	}
+	return sums
+}
+
+/*
+Checks that the plan deletes non-nested paths allowed by the mode whose sizes add up to plan.Freed
+*/
+func checkPlan(t *testing.T, root *Directory, plan CleanupPlan, mode CleanupMode) {
+	t.Helper()
+	var freed int
+	for i, path := range plan.Paths {
+		for _, other := range plan.Paths[i+1:] {
+			if path == PATH_SEPARATOR || strings.HasPrefix(other, path+PATH_SEPARATOR) {
+				t.Fatalf("plan %+v deletes %v inside of %v", plan, other, path)
+			}
+		}
+
+		if dir, err := root.FindDirectory(path); err == nil {
+			if mode == FILES_ONLY {
+				t.Fatalf("plan %+v deletes directory %v", plan, path)
+			}
+			freed += dir.CalculateSize()
+			continue
+		}
+		parentPath, name := path[:strings.LastIndex(path, PATH_SEPARATOR)+1], path[strings.LastIndex(path, PATH_SEPARATOR)+1:]
+		parent, err := root.FindDirectory(parentPath)
+		if err != nil || parent.files[name] == nil {
+			t.Fatalf("plan %+v deletes %v, which doesn't exist", plan, path)
+		}
+		if mode == DIRECTORIES_ONLY {
+			t.Fatalf("plan %+v deletes file %v", plan, path)
+		}
+		freed += parent.files[name].size
+	}
+	if freed != plan.Freed {
+		t.Fatalf("plan %+v frees %v", plan, freed)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+/*
+SortOrder determines the order in which the contents of a directory are exported
+*/
+type SortOrder int
+
+const (
+	BY_NAME SortOrder = iota
+	// Largest first, ties are ordered by name
+	BY_SIZE
+)
+
+func ParseSortOrder(order string) (SortOrder, error) {
+	switch order {
+	case "name":
+		return BY_NAME, nil
+	case "size":
+		return BY_SIZE, nil
+	}
+	return BY_NAME, fmt.Errorf("unknown sort order %q, expected name or size", order)
+}
+
+/*
+entry is a file or subdirectory of a directory, dir is nil for files
+*/
+type entry struct {
+	name string
+	size int
+	dir  *Directory
+}
+
+/*
+Returns the files and subdirectories of the directory in the given order
+*/
+func (dir *Directory) sortedEntries(order SortOrder) []entry {
+	entries := []entry{}
+	for _, subdir := range dir.subdirectories {
+		entries = append(entries, entry{subdir.name, subdir.CalculateSize(), subdir})
+	}
+	for _, file := range dir.files {
+		entries = append(entries, entry{file.name, file.size, nil})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if order == BY_SIZE && entries[i].size != entries[j].size {
+			return entries[i].size > entries[j].size
+		}
+		return entries[i].name < entries[j].name
+	})
+	return entries
+}
+
+/*
+Writes the directory in the style of the tree command, directories are suffixed w/ '/' and every entry is followed by its size
+*/
+func (dir *Directory) ExportTree(w io.Writer, order SortOrder) error {
+	if _, err := fmt.Fprintf(w, "%v (%v)\n", dir.name, dir.CalculateSize()); err != nil {
+		return err
+	}
+	return dir.exportTreeHelper(w, order, "")
+}
+
+func (dir *Directory) exportTreeHelper(w io.Writer, order SortOrder, prefix string) error {
+	entries := dir.sortedEntries(order)
+	for i, e := range entries {
+		branch, indent := "├── ", "│   "
+		if i == len(entries)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		name := e.name
+		if e.dir != nil {
+			name += PATH_SEPARATOR
+		}
+		if _, err := fmt.Fprintf(w, "%v%v%v (%v)\n", prefix, branch, name, e.size); err != nil {
+			return err
+		}
+		if e.dir != nil {
+			if err := e.dir.exportTreeHelper(w, order, prefix+indent); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+/*
+jsonNode is the JSON representation of a file or directory, only directories have children
+*/
+type jsonNode struct {
+	Name     string      `json:"name"`
+	Type     string      `json:"type"`
+	Size     int         `json:"size"`
+	Children []*jsonNode `json:"children,omitempty"`
+}
+
+func (dir *Directory) toJsonNode(order SortOrder) *jsonNode {
+	node := &jsonNode{dir.name, "dir", dir.CalculateSize(), []*jsonNode{}}
+	for _, e := range dir.sortedEntries(order) {
+		if e.dir != nil {
+			node.Children = append(node.Children, e.dir.toJsonNode(order))
+		} else {
+			node.Children = append(node.Children, &jsonNode{e.name, "file", e.size, nil})
+		}
+	}
+	return node
+}
+
+/*
+Writes the directory as nested JSON objects w/ the cumulative size of every directory
+*/
+func (dir *Directory) ExportJson(w io.Writer, order SortOrder) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(dir.toJsonNode(order))
+}
+
+/*
+Writes a line per file and directory in the style of 'du -a', directories are listed after their contents w/ their cumulative size
+*/
+func (dir *Directory) ExportDu(w io.Writer, order SortOrder) error {
+	for _, e := range dir.sortedEntries(order) {
+		if e.dir != nil {
+			if err := e.dir.ExportDu(w, order); err != nil {
+				return err
+			}
+		} else if _, err := fmt.Fprintf(w, "%v\t%v\n", e.size, dir.childPath(e.name)); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%v\t%v\n", dir.CalculateSize(), dir.Path())
+	return err
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+Opens the file or directory at name relative to dir, which makes every Directory an fs.FS
+Files have no recorded contents so reading one yields as many zero bytes as its recorded size
+*/
+func (dir *Directory) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." {
+		return &openDirectory{dir: dir, name: "."}, nil
+	}
+
+	current := dir
+	components := strings.Split(name, PATH_SEPARATOR)
+	for i, component := range components {
+		if subdir, exists := current.subdirectories[component]; exists {
+			current = subdir
+			continue
+		}
+		if file, exists := current.files[component]; exists && i == len(components)-1 {
+			return &openFile{file: file}, nil
+		}
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &openDirectory{dir: current, name: current.name}, nil
+}
+
+/*
+Returns the FileInfo of the file or directory at name relative to dir, which makes every Directory an fs.StatFS
+*/
+func (dir *Directory) Stat(name string) (fs.FileInfo, error) {
+	file, err := dir.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return file.Stat()
+}
+
+/*
+fileInfo describes a file or directory, directories report a size of 0 like most filesystems do
+*/
+type fileInfo struct {
+	name  string
+	size  int64
+	isDir bool
+}
+
+func (fi fileInfo) Name() string {
+	return fi.name
+}
+
+func (fi fileInfo) Size() int64 {
+	return fi.size
+}
+
+func (fi fileInfo) Mode() fs.FileMode {
+	if fi.isDir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+func (fi fileInfo) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (fi fileInfo) IsDir() bool {
+	return fi.isDir
+}
+
+func (fi fileInfo) Sys() any {
+	return nil
+}
+
+/*
+openFile is a File opened for reading, its contents are all zero bytes
+*/
+type openFile struct {
+	file   *File
+	offset int64
+}
+
+func (of *openFile) Stat() (fs.FileInfo, error) {
+	return fileInfo{of.file.name, int64(of.file.size), false}, nil
+}
+
+func (of *openFile) Read(b []byte) (int, error) {
+	remaining := int64(of.file.size) - of.offset
+	if remaining <= 0 {
+		return 0, io.EOF
+	}
+	n := len(b)
+	if int64(n) > remaining {
+		n = int(remaining)
+	}
+	for i := 0; i < n; i++ {
+		b[i] = 0
+	}
+	of.offset += int64(n)
+	return n, nil
+}
+
+func (of *openFile) Close() error {
+	return nil
+}
+
+/*
+openDirectory is a Directory opened for reading its entries, entries are returned in name order
+*/
+type openDirectory struct {
+	dir     *Directory
+	name    string
+	entries []fs.DirEntry
+	read    bool
+}
+
+func (od *openDirectory) Stat() (fs.FileInfo, error) {
+	return fileInfo{od.name, 0, true}, nil
+}
+
+func (od *openDirectory) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: od.name, Err: fs.ErrInvalid}
+}
+
+func (od *openDirectory) Close() error {
+	return nil
+}
+
+func (od *openDirectory) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !od.read {
+		od.read = true
+		for _, subdir := range od.dir.subdirectories {
+			od.entries = append(od.entries, fs.FileInfoToDirEntry(fileInfo{subdir.name, 0, true}))
+		}
+		for _, file := range od.dir.files {
+			od.entries = append(od.entries, fs.FileInfoToDirEntry(fileInfo{file.name, int64(file.size), false}))
+		}
+		sort.Slice(od.entries, func(i, j int) bool { return od.entries[i].Name() < od.entries[j].Name() })
+	}
+
+	if n <= 0 {
+		entries := od.entries
+		od.entries = nil
+		return entries, nil
+	}
+	if len(od.entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(od.entries) {
+		n = len(od.entries)
+	}
+	entries := od.entries[:n]
+	od.entries = od.entries[n:]
+	return entries, nil
+}
+
+/*
+Recreates the directory under path on disk, every file is a sparse file w/ its recorded size so nothing is actually written
+*/
+func (dir *Directory) Materialize(path string) error {
+	return fs.WalkDir(dir, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(path, filepath.FromSlash(name))
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		file, err := os.Create(target)
+		if err != nil {
+			return err
+		}
+		if err := file.Truncate(info.Size()); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	})
+}
+
+/*
+Materializes the directory under a new temporary directory and returns its path, the caller is responsible for removing it
+*/
+func (dir *Directory) MaterializeTemp() (string, error) {
+	path, err := os.MkdirTemp("", "day_7_")
+	if err != nil {
+		return "", err
+	}
+	if err := dir.Materialize(path); err != nil {
+		os.RemoveAll(path)
+		return "", err
+	}
+	return path, nil
+}
+
+/*
+Walks the real directory at path, summing the apparent size of the files in each directory (like 'du -b'), and compares the
+totals against CalculateSize - returns an error describing the first directory that doesn't match
+*/
+func (dir *Directory) VerifyMaterialized(path string) error {
+	sizes := map[string]int64{}
+	err := filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		// Attribute the size of the file to every directory containing it
+		for parent := filepath.Dir(name); ; parent = filepath.Dir(parent) {
+			sizes[parent] += info.Size()
+			if parent == path || parent == filepath.Dir(parent) {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var mismatch error
+	dir.Walk(func(subdir *Directory) {
+		relative := strings.TrimPrefix(subdir.Path(), dir.Path())
+		target := filepath.Join(path, filepath.FromSlash(relative))
+		if mismatch == nil && sizes[target] != int64(subdir.CalculateSize()) {
+			mismatch = fmt.Errorf("%v: calculated size %v, but %v holds %v", subdir.Path(), subdir.CalculateSize(), target, sizes[target])
+		}
+	})
+	return mismatch
+}
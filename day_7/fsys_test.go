@@ -0,0 +1,91 @@
+package main
+
+import (
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// Transcript from the puzzle's example
+const EXAMPLE_TRANSCRIPT = `$ cd /
+$ ls
+dir a
+14848514 b.txt
+8504156 c.dat
+dir d
+$ cd a
+$ ls
+dir e
+29116 f
+2557 g
+62596 h.lst
+$ cd e
+$ ls
+584 i
+$ cd ..
+$ cd ..
+$ cd d
+$ ls
+4060174 j
+8033020 d.log
+5626152 d.ext
+7214296 k`
+
+func parseExample(t *testing.T) *Directory {
+	t.Helper()
+	root, err := constructFs(strings.Split(EXAMPLE_TRANSCRIPT, "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestDirectoryFS(t *testing.T) {
+	root := parseExample(t)
+	if err := fstest.TestFS(root, "a", "a/e/i", "a/f", "b.txt", "d/d.log", "d/k"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMaterialize(t *testing.T) {
+	root := parseExample(t)
+	path := t.TempDir()
+	if err := root.Materialize(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := root.VerifyMaterialized(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cross-check the total against du, which also counts the apparent size of the directories themselves
+	// -b is a GNU extension, BSD/macOS du rejects it
+	out, err := exec.Command("du", "-sb", path).Output()
+	if err != nil {
+		t.Skipf("du -sb is not available: %v", err)
+	}
+	total, err := strconv.ParseInt(strings.Fields(string(out))[0], 10, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = filepath.WalkDir(path, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		total -= info.Size()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(root.CalculateSize()); total != want {
+		t.Errorf("du reports %v bytes of files, want %v", total, want)
+	}
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -23,7 +25,12 @@ type (
 		name            string
 		parentDirectory *Directory
 		subdirectories  map[string]*Directory
-		files           []*File
+		files           map[string]*File
+		// listed is true once the contents of the directory have been seen in ls output
+		listed bool
+		// size is cached by CalculateSize until the contents of the directory or any of its subdirectories change
+		size      int
+		sizeValid bool
 	}
 
 	File struct {
@@ -33,78 +40,283 @@ type (
 )
 
 func main() {
-	lines := openInputFile()
-	fs := constructFs(lines)
-	root := fs.NavigateToRootDir()
+	top := flag.Int("top", 0, "also report the k largest directories")
+	find := flag.String("find", "", "also report the size of the directory at the given path")
+	export := flag.String("export", "", "print the filesystem instead of solving the puzzle, one of: tree, json, du")
+	sortOrder := flag.String("sort", "name", "order of exported directory contents, one of: name, size")
+	diskSize := flag.Int("disk-size", AVAILABLE_SPACE, "total size of the filesystem")
+	spaceNeeded := flag.Int("space-needed", SPACE_NEEDED, "unused space needed to run the update")
+	cleanup := flag.String("cleanup", "", "also plan the smallest set of deletions that frees enough space, one of: dirs, files, both")
+	merge := flag.String("merge", "", "comma-separated list of transcripts to merge instead of the input file, each as path[@start dir]")
+	materialize := flag.Bool("materialize", false, "recreate the filesystem in a temp directory using sparse files, verify its size and remove it")
+	flag.Parse()
+
+	var root *Directory
+	var err error
+	if *merge != "" {
+		root, err = mergeTranscriptFiles(strings.Split(*merge, ","))
+	} else {
+		root, err = constructFs(openInputFile())
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *materialize {
+		path, err := root.MaterializeTemp()
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = root.VerifyMaterialized(path)
+		if removeErr := os.RemoveAll(path); err == nil {
+			err = removeErr
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Materialized filesystem to a temporary directory, the size of every directory matches")
+	}
+
+	if *export != "" {
+		if err := exportFs(root, *export, *sortOrder); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
 	log.Printf("Part 1: The sum of the size of directories w/ size <= 100000 is %v", root.SumDirectorySizeUnderMaxSize(100000))
 
-	spaceAvailable := AVAILABLE_SPACE - root.CalculateSize()
-	freeSpaceNeededForUpdate := SPACE_NEEDED - spaceAvailable
-	log.Printf("Part 2: The smallest directory over 30000000 is %v", root.DetermineSmallestEligibleDirSize(freeSpaceNeededForUpdate))
+	spaceAvailable := *diskSize - root.CalculateSize()
+	freeSpaceNeededForUpdate := *spaceNeeded - spaceAvailable
+	log.Printf("Part 2: The smallest directory over %v is %v", *spaceNeeded, root.DetermineSmallestEligibleDirSize(freeSpaceNeededForUpdate))
+
+	if *cleanup != "" {
+		mode, err := ParseCleanupMode(*cleanup)
+		if err != nil {
+			log.Fatal(err)
+		}
+		plan, err := root.PlanCleanup(freeSpaceNeededForUpdate, mode)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Cleanup: Deleting %v paths frees %v of the %v needed (exact: %v)", len(plan.Paths), plan.Freed, freeSpaceNeededForUpdate, plan.Exact)
+		for _, path := range plan.Paths {
+			log.Printf("Cleanup: %v", path)
+		}
+	}
+
+	for i, dir := range root.LargestDirectories(*top) {
+		log.Printf("Largest directory #%v: %v (%v)", i+1, dir.Path(), dir.CalculateSize())
+	}
+	if *find != "" {
+		dir, err := root.FindDirectory(*find)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("The size of %v is %v", dir.Path(), dir.CalculateSize())
+	}
+}
+
+/*
+Merges the transcript files into a single filesystem, logging any conflicts and directories that were never listed
+*/
+func mergeTranscriptFiles(specs []string) (*Directory, error) {
+	transcripts := []Transcript{}
+	for _, spec := range specs {
+		transcript, err := ReadTranscript(spec)
+		if err != nil {
+			return nil, err
+		}
+		transcripts = append(transcripts, transcript)
+	}
+
+	root, report, err := MergeTranscripts(transcripts)
+	if err != nil {
+		return nil, err
+	}
+	for _, conflict := range report.Conflicts {
+		log.Printf("Conflict: %v", conflict)
+	}
+	for _, path := range report.Unlisted {
+		log.Printf("Never listed: %v", path)
+	}
+	return root, nil
+}
+
+/*
+Writes the filesystem to stdout in the given format
+*/
+func exportFs(root *Directory, format string, sortOrder string) error {
+	order, err := ParseSortOrder(sortOrder)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "tree":
+		return root.ExportTree(os.Stdout, order)
+	case "json":
+		return root.ExportJson(os.Stdout, order)
+	case "du":
+		return root.ExportDu(os.Stdout, order)
+	}
+	return fmt.Errorf("unknown export format %q, expected tree, json or du", format)
 }
 
 /*
 Constructs the layout of the filesystem based on commands and ls output given in input file
+Supports relative and absolute cd paths (including 'cd /' at any point) and repeated ls of the same directory, returns an error
+naming the offending line for unknown commands, malformed output or cd into a directory that hasn't been listed
+*/
+func constructFs(lines []string) (*Directory, error) {
+	root := newDirectory(PATH_SEPARATOR, nil)
+	if err := applyTranscript(root, lines, transcriptOptions{}); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+/*
+transcriptOptions relax how a transcript is applied when it is only part of a larger session
+*/
+type transcriptOptions struct {
+	// Directories that are cd'ed into before they have been listed are created instead of failing
+	implicitDirs bool
+	// Called w/ the line # of every ConflictError instead of failing, conflicting entries keep their first listing
+	onConflict func(line int, err *ConflictError)
+}
+
+/*
+Applies the commands and ls output of a transcript to the filesystem, starting in the given directory
 */
-func constructFs(lines []string) *Directory {
-	var currentDir *Directory
-	for _, line := range lines {
+func applyTranscript(start *Directory, lines []string, opts transcriptOptions) error {
+	currentDir := start
+	var listing bool
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		var err error
 		switch {
-		case strings.HasPrefix(line, CMD):
-			if strings.HasPrefix(line, fmt.Sprintf("%v %v", CMD, CD)) {
-				currentDir = changeDirectories(currentDir, line)
+		case fields[0] == CMD:
+			listing = false
+			if len(fields) < 2 {
+				return fmt.Errorf("line %v: missing command in %q", i+1, line)
+			}
+			switch fields[1] {
+			case CD:
+				// Like the names in ls output, the path is everything after the command so it may contain spaces
+				prefix := CMD + " " + CD + " "
+				if !strings.HasPrefix(line, prefix) || len(line) == len(prefix) {
+					return fmt.Errorf("line %v: expected '%v %v <dir>', got %q", i+1, CMD, CD, line)
+				}
+				path := line[len(prefix):]
+				dir, err := currentDir.resolveDirectory(path, opts.implicitDirs)
+				if err != nil {
+					return fmt.Errorf("line %v: cannot cd: %w", i+1, err)
+				}
+				currentDir = dir
+			case LS:
+				if len(fields) != 2 {
+					return fmt.Errorf("line %v: expected '%v %v', got %q", i+1, CMD, LS, line)
+				}
+				listing = true
+				currentDir.listed = true
+			default:
+				return fmt.Errorf("line %v: unknown command %q", i+1, fields[1])
+			}
+		case !listing:
+			return fmt.Errorf("line %v: output %q is not part of an ls", i+1, line)
+		case fields[0] == DIR:
+			if len(fields) < 2 {
+				return fmt.Errorf("line %v: missing directory name in %q", i+1, line)
 			}
-		case strings.HasPrefix(line, DIR):
-			dirName := strings.Split(line, " ")[1]
-			currentDir.AddSubdirectory(dirName)
+			err = currentDir.AddSubdirectory(strings.TrimPrefix(line, DIR+" "))
 		default:
-			splitLine := strings.Split(line, " ")
-			size, _ := strconv.Atoi(splitLine[0])
-			name := splitLine[1]
-			file := &File{name, size}
-			currentDir.AddFile(file)
+			sizeStr, name, found := strings.Cut(line, " ")
+			size, atoiErr := strconv.Atoi(sizeStr)
+			if !found || atoiErr != nil || size < 0 {
+				return fmt.Errorf("line %v: expected '<size> <name>' or '%v <name>', got %q", i+1, DIR, line)
+			}
+			err = currentDir.AddFile(&File{name, size})
+		}
+
+		var conflict *ConflictError
+		if errors.As(err, &conflict) && opts.onConflict != nil {
+			opts.onConflict(i+1, conflict)
+		} else if err != nil {
+			return fmt.Errorf("line %v: %w", i+1, err)
 		}
 	}
-	return currentDir
+	return nil
 }
 
 /*
-Given some directory currentDir and cd cmd, return the Directory object associated w/ the named dir in the cmd (EX 'abcd' for cmd $ cd abcd)
-The name of the directory given in cmd must either be a subdirectory of currentDir or must be '..' indicating
-that the directory should be changed to the parent directory of currentDir
+ConflictError is returned when a path is listed in a way that contradicts an earlier listing
 */
-func changeDirectories(currentDir *Directory, cmdStr string) *Directory {
-	var dir *Directory
+type ConflictError struct {
+	Path   string
+	Reason string
+}
+
+func (ce *ConflictError) Error() string {
+	return fmt.Sprintf("%v %v", ce.Path, ce.Reason)
+}
 
-	cmd := strings.Split(cmdStr, " ")
-	cdDirName := cmd[2]
+func newDirectory(name string, parent *Directory) *Directory {
+	return &Directory{name: name, parentDirectory: parent, subdirectories: map[string]*Directory{}, files: map[string]*File{}}
+}
 
-	if currentDir != nil {
-		if cdDirName == PARENT_DIR_ALIAS {
-			dir = currentDir.parentDirectory
-		} else {
-			dir = currentDir.subdirectories[cdDirName]
+/*
+Adds a file to the directory, listing a file that is already known is a no-op as long as its size hasn't changed
+*/
+func (dir *Directory) AddFile(file *File) error {
+	if existing, exists := dir.files[file.name]; exists {
+		if existing.size != file.size {
+			return &ConflictError{dir.childPath(file.name), fmt.Sprintf("was listed w/ size %v and %v", existing.size, file.size)}
 		}
-	} else {
-		// Initialize root directory
-		dir = &Directory{cdDirName, nil, map[string]*Directory{}, []*File{}}
+		return nil
 	}
-	return dir
+	if _, exists := dir.subdirectories[file.name]; exists {
+		return &ConflictError{dir.childPath(file.name), "is a directory, not a file"}
+	}
+	dir.files[file.name] = file
+	dir.invalidateSize()
+	return nil
 }
 
-func (dir *Directory) AddFile(file *File) {
-	dir.files = append(dir.files, file)
+/*
+Adds a subdirectory to the directory, listing a subdirectory that is already known keeps its contents
+*/
+func (dir *Directory) AddSubdirectory(subDirName string) error {
+	if _, exists := dir.subdirectories[subDirName]; exists {
+		return nil
+	}
+	if _, exists := dir.files[subDirName]; exists {
+		return &ConflictError{dir.childPath(subDirName), "is a file, not a directory"}
+	}
+	dir.subdirectories[subDirName] = newDirectory(subDirName, dir)
+	dir.invalidateSize()
+	return nil
 }
 
-func (dir *Directory) AddSubdirectory(subDirName string) {
-	dir.subdirectories[subDirName] = &Directory{subDirName, dir, map[string]*Directory{}, []*File{}}
+/*
+Clears the cached size of the directory and all of its parents
+*/
+func (dir *Directory) invalidateSize() {
+	for d := dir; d != nil && d.sizeValid; d = d.parentDirectory {
+		d.sizeValid = false
+	}
 }
 
 /*
 Calculates the size of given directory based on the size of its child files and subdirectories
+Sizes are computed in a single post-order pass and cached on every directory visited, so repeated calls are O(1)
 */
 func (dir *Directory) CalculateSize() int {
+	if dir.sizeValid {
+		return dir.size
+	}
 	var dirSize int
 	for _, file := range dir.files {
 		dirSize += file.size
@@ -112,6 +324,7 @@ func (dir *Directory) CalculateSize() int {
 	for _, subDir := range dir.subdirectories {
 		dirSize += subDir.CalculateSize()
 	}
+	dir.size, dir.sizeValid = dirSize, true
 	return dirSize
 }
 
@@ -127,11 +340,8 @@ func (dir *Directory) NavigateToRootDir() *Directory {
 
 func (dir *Directory) SumDirectorySizeUnderMaxSize(maxSize int) int {
 	var sum int
-	if dir.CalculateSize() <= maxSize {
-		sum += dir.CalculateSize()
-	}
-	for _, subdir := range dir.subdirectories {
-		sum += subdir.SumDirectorySizeUnderMaxSize(maxSize)
+	for _, subdir := range dir.FilterBySize(func(size int) bool { return size <= maxSize }) {
+		sum += subdir.CalculateSize()
 	}
 	return sum
 }
@@ -141,14 +351,11 @@ func (dir *Directory) DetermineSmallestEligibleDirSize(minSize int) int {
 }
 
 func (dir *Directory) DetermineSmallestEligibleDirSizeHelper(minSize int, minObserved int) int {
-	dirSize := dir.CalculateSize()
-	if dirSize >= minSize && dirSize < minObserved {
-		minObserved = dirSize
-	}
-
-	for _, subdir := range dir.subdirectories {
-		minObserved = subdir.DetermineSmallestEligibleDirSizeHelper(minSize, minObserved)
-	}
+	dir.Walk(func(subdir *Directory) {
+		if dirSize := subdir.CalculateSize(); dirSize >= minSize && dirSize < minObserved {
+			minObserved = dirSize
+		}
+	})
 	return minObserved
 }
 
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConstructFs(t *testing.T) {
+	tests := []struct {
+		name       string
+		transcript string
+		wantSize   int
+		wantErr    string
+	}{
+		{"puzzle example", EXAMPLE_TRANSCRIPT, 48381165, ""},
+		{"whitespace-only lines are skipped", "$ cd /\n   \n$ ls\n\t\n100 a", 100, ""},
+		{"names w/ spaces", "$ cd /\n$ ls\ndir my dir\n$ cd my dir\n$ ls\n200 my file\n$ cd /my dir\n$ ls\n200 my file", 200, ""},
+		{"cd w/o a directory", "$ cd /\n$ cd ", 0, "line 2: expected '$ cd <dir>'"},
+		{"cd into an unknown directory", "$ cd /\n$ ls\ndir a\n$ cd b", 0, "line 4: cannot cd"},
+		{"unknown command", "$ cd /\n$ rm a", 0, `line 2: unknown command "rm"`},
+		{"output outside of ls", "$ cd /\n100 a", 0, "line 2: output"},
+		{"malformed file", "$ cd /\n$ ls\nabc", 0, "line 3: expected '<size> <name>'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := constructFs(strings.Split(tt.transcript, "\n"))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := root.CalculateSize(); got != tt.wantSize {
+				t.Errorf("CalculateSize() = %v, want %v", got, tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestLargestDirectories(t *testing.T) {
+	root := parseExample(t)
+	tests := []struct {
+		k         int
+		wantSizes []int
+	}{
+		{-1, []int{}},
+		{0, []int{}},
+		{2, []int{48381165, 24933642}},
+		{10, []int{48381165, 24933642, 94853, 584}},
+	}
+	for _, tt := range tests {
+		sizes := []int{}
+		for _, dir := range root.LargestDirectories(tt.k) {
+			sizes = append(sizes, dir.CalculateSize())
+		}
+		if !reflect.DeepEqual(sizes, tt.wantSizes) {
+			t.Errorf("LargestDirectories(%v) sizes = %v, want %v", tt.k, sizes, tt.wantSizes)
+		}
+	}
+}
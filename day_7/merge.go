@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+/*
+Transcript is a single terminal session, which starts in the directory at the absolute path Start
+*/
+type Transcript struct {
+	Name  string
+	Start string
+	Lines []string
+}
+
+/*
+Reads a transcript from a file, spec is the path to the file optionally followed by '@' and the absolute path of the
+directory the session starts in (EX session.txt@/a/e), sessions start in the root directory by default
+*/
+func ReadTranscript(spec string) (Transcript, error) {
+	path, start, found := strings.Cut(spec, "@")
+	if !found {
+		start = PATH_SEPARATOR
+	}
+	if !strings.HasPrefix(start, PATH_SEPARATOR) {
+		return Transcript{}, fmt.Errorf("%v: start directory %q must be an absolute path", path, start)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Transcript{}, err
+	}
+	return Transcript{path, start, strings.Split(string(data), "\n")}, nil
+}
+
+/*
+MergeConflict is a ConflictError found on a given line of a transcript
+*/
+type MergeConflict struct {
+	Transcript string
+	Line       int
+	Err        *ConflictError
+}
+
+func (mc MergeConflict) String() string {
+	return fmt.Sprintf("%v line %v: %v", mc.Transcript, mc.Line, mc.Err)
+}
+
+/*
+MergeReport describes what could not be reconciled when merging transcripts
+Unlisted holds the paths of directories that are known to exist but whose contents were never listed, so their sizes may be
+too small
+*/
+type MergeReport struct {
+	Conflicts []MergeConflict
+	Unlisted  []string
+}
+
+/*
+Merges several transcripts into a single filesystem, in order
+Directories that are cd'ed into before being listed are created, and entries that contradict an earlier listing are reported as
+conflicts rather than failing the merge, in which case the first listing wins
+*/
+func MergeTranscripts(transcripts []Transcript) (*Directory, MergeReport, error) {
+	root := newDirectory(PATH_SEPARATOR, nil)
+	report := MergeReport{[]MergeConflict{}, []string{}}
+	for _, transcript := range transcripts {
+		start, err := root.resolveDirectory(transcript.Start, true)
+		if err != nil {
+			return nil, report, fmt.Errorf("%v: %w", transcript.Name, err)
+		}
+		opts := transcriptOptions{
+			implicitDirs: true,
+			onConflict: func(line int, err *ConflictError) {
+				report.Conflicts = append(report.Conflicts, MergeConflict{transcript.Name, line, err})
+			},
+		}
+		if err := applyTranscript(start, transcript.Lines, opts); err != nil {
+			return nil, report, fmt.Errorf("%v: %w", transcript.Name, err)
+		}
+	}
+
+	root.Walk(func(dir *Directory) {
+		if !dir.listed {
+			report.Unlisted = append(report.Unlisted, dir.Path())
+		}
+	})
+	return root, report, nil
+}
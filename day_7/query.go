@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	PATH_SEPARATOR = "/"
+)
+
+/*
+Returns the subdirectories of the directory sorted by name
+*/
+func (dir *Directory) SortedSubdirectories() []*Directory {
+	subdirs := []*Directory{}
+	for _, subdir := range dir.subdirectories {
+		subdirs = append(subdirs, subdir)
+	}
+	sort.Slice(subdirs, func(i, j int) bool { return subdirs[i].name < subdirs[j].name })
+	return subdirs
+}
+
+/*
+Visits the directory and all of its subdirectories in pre-order, subdirectories are visited in name order
+*/
+func (dir *Directory) Walk(visit func(*Directory)) {
+	visit(dir)
+	for _, subdir := range dir.SortedSubdirectories() {
+		subdir.Walk(visit)
+	}
+}
+
+/*
+Returns the absolute path of the directory, EX /a/e
+*/
+func (dir *Directory) Path() string {
+	if dir.parentDirectory == nil {
+		return PATH_SEPARATOR
+	}
+	return dir.parentDirectory.childPath(dir.name)
+}
+
+/*
+Returns the absolute path of a file or subdirectory named name in the directory
+*/
+func (dir *Directory) childPath(name string) string {
+	if dir.parentDirectory == nil {
+		return PATH_SEPARATOR + name
+	}
+	return dir.Path() + PATH_SEPARATOR + name
+}
+
+/*
+Finds a directory by path, absolute paths are resolved from the root directory and relative paths from dir
+'..' refers to the parent directory
+*/
+func (dir *Directory) FindDirectory(path string) (*Directory, error) {
+	return dir.resolveDirectory(path, false)
+}
+
+/*
+Resolves a path the same way as FindDirectory, if create is set missing directories along the path are created instead of failing
+*/
+func (dir *Directory) resolveDirectory(path string, create bool) (*Directory, error) {
+	current := dir
+	if strings.HasPrefix(path, PATH_SEPARATOR) {
+		current = dir.NavigateToRootDir()
+	}
+	for _, name := range strings.Split(path, PATH_SEPARATOR) {
+		switch name {
+		case "", ".":
+			continue
+		case PARENT_DIR_ALIAS:
+			if current.parentDirectory == nil {
+				return nil, fmt.Errorf("%v: root directory has no parent", path)
+			}
+			current = current.parentDirectory
+		default:
+			if _, exists := current.subdirectories[name]; !exists {
+				if !create {
+					return nil, fmt.Errorf("%v: no directory %q in %v", path, name, current.Path())
+				}
+				if err := current.AddSubdirectory(name); err != nil {
+					return nil, err
+				}
+			}
+			current = current.subdirectories[name]
+		}
+	}
+	return current, nil
+}
+
+/*
+Returns the directory and all of its subdirectories whose size satisfies the predicate, in pre-order
+*/
+func (dir *Directory) FilterBySize(predicate func(size int) bool) []*Directory {
+	result := []*Directory{}
+	dir.Walk(func(subdir *Directory) {
+		if predicate(subdir.CalculateSize()) {
+			result = append(result, subdir)
+		}
+	})
+	return result
+}
+
+/*
+Returns the k largest directories (including dir itself) in descending order of size, ties are kept in walk order
+Returns no directories if k is not positive
+*/
+func (dir *Directory) LargestDirectories(k int) []*Directory {
+	if k <= 0 {
+		return []*Directory{}
+	}
+	all := dir.FilterBySize(func(int) bool { return true })
+	sort.SliceStable(all, func(i, j int) bool { return all[i].CalculateSize() > all[j].CalculateSize() })
+	if k < len(all) {
+		all = all[:k]
+	}
+	return all
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"os"
 	"strconv"
@@ -8,32 +10,163 @@ import (
 )
 
 func main() {
-	trees := parseInputFile()
+	render := flag.String("render", "", "print a map of the forest instead of solving the puzzle, one of: visibility, heatmap")
+	colored := flag.Bool("color", false, "use ANSI colors when rendering")
+	imagePath := flag.String("image", "", "also write the rendered map to a .png or .pgm image, defaults to the heatmap")
+	directionSpec := flag.String("directions", "", "also solve both parts looking along the given directions, one of: "+DIRECTIONS_USAGE)
+	top := flag.Int("top", 0, "also report the k trees w/ the highest scenic scores that satisfy the placement constraints")
+	minHeight := flag.Int("min-height", 0, "placement constraint: minimum height of the tree")
+	minEdgeDistance := flag.Int("min-edge-distance", 0, "placement constraint: minimum # of trees between the tree and the edge")
+	visibleOnly := flag.Bool("visible-only", false, "placement constraint: the tree must be visible from outside the forest")
+	vantage := flag.String("vantage", "", "also report what can be seen from row,col[,height] along -directions, height defaults to the tree there")
+	flag.Parse()
+
+	trees, err := parseInputFile()
+	if err != nil {
+		log.Fatal(err)
+	}
 	trees.AssignVisibility()
+
+	if *imagePath != "" {
+		if err := writeForestImage(trees, *render, *imagePath); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *render != "" {
+		if err := renderForest(trees, *render, *colored); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	log.Printf("Part 1: There are %v visible trees in the forest", trees.CountVisible())
-	log.Printf("Part 2: The highest possible scenic score is %v", trees.GetMaxScenicScore())
+	log.Printf("Part 2: The highest possible scenic score is %v", trees.ScenicScores().Max())
+
+	if *top > 0 {
+		constraints := PlacementConstraints{*minHeight, *minEdgeDistance, *visibleOnly}
+		for i, placement := range trees.TopPlacements(*top, constraints) {
+			log.Printf("#%v: Tree at %v,%v of height %v w/ a scenic score of %v",
+				i+1, placement.Position.Row, placement.Position.Col, placement.Height, placement.ScenicScore)
+		}
+	}
+
+	if *directionSpec == "" && *vantage == "" {
+		return
+	}
+	directions := FOUR_WAY
+	if *directionSpec != "" {
+		if directions, err = ParseDirections(*directionSpec); err != nil {
+			log.Fatal(err)
+		}
+		trees.AssignVisibilityAlong(directions)
+		log.Printf("Along %v directions: There are %v visible trees in the forest", len(directions), trees.CountVisible())
+		log.Printf("Along %v directions: The highest possible scenic score is %v", len(directions), trees.GetMaxScenicScoreAlong(directions))
+	}
+	if *vantage != "" {
+		if err := reportLineOfSight(trees, *vantage, directions); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+/*
+Logs the viewing distance in every direction from a vantage point given as row,col[,height]
+*/
+func reportLineOfSight(forest Forest, vantage string, directions []Direction) error {
+	fields := strings.Split(vantage, ",")
+	if len(fields) < 2 || len(fields) > 3 {
+		return fmt.Errorf("invalid vantage point %q, expected row,col[,height]", vantage)
+	}
+	values := []int{}
+	for _, field := range fields {
+		value, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return fmt.Errorf("invalid vantage point %q: %w", vantage, err)
+		}
+		values = append(values, value)
+	}
+
+	from := Position{values[0], values[1]}
+	var sights []Sight
+	if len(values) == 3 {
+		sights = forest.LineOfSight(from, values[2], directions)
+	} else {
+		var err error
+		if sights, err = forest.LineOfSightFromTree(from, directions); err != nil {
+			return err
+		}
+	}
+
+	for _, sight := range sights {
+		end := "the edge of the forest"
+		if sight.Blocked {
+			last := sight.Trees[len(sight.Trees)-1]
+			end = fmt.Sprintf("a tree of height %v at %v,%v", forest[last.Row][last.Col].height, last.Row, last.Col)
+		}
+		log.Printf("Looking %v,%v from %v,%v: %v trees until %v", sight.Direction.DRow, sight.Direction.DCol, from.Row, from.Col, sight.Distance, end)
+	}
+	return nil
+}
+
+/*
+Prints the forest as the given kind of map, visibility must already be assigned
+*/
+func renderForest(forest Forest, kind string, colored bool) error {
+	switch kind {
+	case "visibility":
+		return forest.RenderVisibility(os.Stdout, colored)
+	case "heatmap":
+		return forest.ScenicScores().RenderHeatmap(os.Stdout, colored)
+	}
+	return fmt.Errorf("unknown map %q, expected visibility or heatmap", kind)
+}
+
+/*
+Writes the given kind of map to an image, visibility must already be assigned
+*/
+func writeForestImage(forest Forest, kind string, path string) error {
+	switch kind {
+	case "visibility":
+		return WriteImage(path, forest.VisibilityImage())
+	case "", "heatmap":
+		return WriteImage(path, forest.ScenicScores().Image())
+	}
+	return fmt.Errorf("unknown map %q, expected visibility or heatmap", kind)
 }
 
 /*
 Parses input file for day_8 AOC 2022 task
 */
-func parseInputFile() Forest {
+func parseInputFile() (Forest, error) {
 	data, err := os.ReadFile("resources/input")
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+	return parseForest(string(data))
+}
 
+/*
+Parses a forest w/ one row per line and one digit per tree
+Every row must have the same # of trees, trailing empty lines are ignored
+*/
+func parseForest(data string) (Forest, error) {
 	trees := [][]*Tree{}
-	splitLines := strings.Split(string(data), "\n")
-	for _, line := range splitLines {
+	splitLines := strings.Split(strings.TrimRight(data, "\n"), "\n")
+	for i, line := range splitLines {
 		row := []*Tree{}
-		for _, val := range line {
-			heightVal, _ := strconv.Atoi(string(val))
+		for j, val := range line {
+			heightVal, err := strconv.Atoi(string(val))
+			if err != nil {
+				return nil, fmt.Errorf("line %v, column %v: tree height %q is not a digit", i+1, j+1, val)
+			}
 			row = append(row, &Tree{heightVal, false})
 		}
+		if len(trees) > 0 && len(row) != len(trees[0]) {
+			return nil, fmt.Errorf("line %v: row has %v trees, expected %v", i+1, len(row), len(trees[0]))
+		}
 		trees = append(trees, row)
 	}
-	return trees
+	return trees, nil
 }
 
 /*
@@ -64,11 +197,15 @@ func (forest Forest) AssignVisibility() {
 		}
 	}
 
-	// column visibility
-	numCols := len(forest[0])
+	// column visibility, a row that is too short to reach a column acts as an edge of the forest
+	numCols := forest.NumCols()
 	for i := 0; i < numCols; i++ {
 		maxObserved := -1
 		for j := 0; j < len(forest); j++ {
+			if i >= len(forest[j]) {
+				maxObserved = -1
+				continue
+			}
 			tree := forest[j][i]
 			if tree.height > maxObserved {
 				tree.visible = true
@@ -78,6 +215,10 @@ func (forest Forest) AssignVisibility() {
 
 		maxObserved = -1
 		for j := len(forest) - 1; j >= 0; j-- {
+			if i >= len(forest[j]) {
+				maxObserved = -1
+				continue
+			}
 			tree := forest[j][i]
 			if tree.height > maxObserved {
 				tree.visible = true
@@ -104,18 +245,17 @@ func (forest Forest) CountVisible() int {
 
 /*
 Get the value of the tree w/ the highest scenic score
-scenic score is determined by multiplying the # of trees that can be scene from a given tree from each direction
+scenic score is determined by multiplying the # of trees that can be scene from a given tree from each direction, so trees on
+the edge of the forest have a scenic score of 0
 */
 func (forest Forest) GetMaxScenicScore() int {
-	maxRows := len(forest)
-	maxCols := len(forest[0])
 	var maxScenicScore int
 	for i, row := range forest {
 		for j, tree := range row {
 
 			// visibility score to the right
 			var rightScore int
-			for z := j + 1; z < maxRows; z++ {
+			for z := j + 1; z < len(row); z++ {
 				otherTree := row[z]
 				if otherTree.height < tree.height {
 					rightScore++
@@ -139,7 +279,7 @@ func (forest Forest) GetMaxScenicScore() int {
 
 			// visibility score going down
 			var downScore int
-			for z := i + 1; z < maxCols; z++ {
+			for z := i + 1; z < len(forest) && j < len(forest[z]); z++ {
 				otherTree := forest[z][j]
 				if otherTree.height < tree.height {
 					downScore++
@@ -151,7 +291,7 @@ func (forest Forest) GetMaxScenicScore() int {
 
 			// visibility score going up
 			var upScore int
-			for z := i - 1; z >= 0; z-- {
+			for z := i - 1; z >= 0 && j < len(forest[z]); z-- {
 				otherTree := forest[z][j]
 				if otherTree.height < tree.height {
 					upScore++
@@ -161,12 +301,7 @@ func (forest Forest) GetMaxScenicScore() int {
 				}
 			}
 
-			scenicScore := 1
-			for _, score := range []int{leftScore, rightScore, downScore, upScore} {
-				if score > 0 {
-					scenicScore *= score
-				}
-			}
+			scenicScore := leftScore * rightScore * downScore * upScore
 
 			if scenicScore > maxScenicScore {
 				maxScenicScore = scenicScore
@@ -190,13 +325,14 @@ Forest is an arrangement of trees in rows/columns
 type Forest [][]*Tree
 
 /*
-Generic function type used to align trees to assign them visibility values
-*/
-type AlignTreesFunc func(forest Forest) Forest
-
-/*
-Trees are already aligned left-to-right row-wise so this is a pass-through function
+Returns the length of the longest row in the forest
 */
-func LeftAlign(forest Forest) Forest {
-	return forest
+func (forest Forest) NumCols() int {
+	var numCols int
+	for _, row := range forest {
+		if len(row) > numCols {
+			numCols = len(row)
+		}
+	}
+	return numCols
 }
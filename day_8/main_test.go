@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetMaxScenicScore(t *testing.T) {
+	tests := []struct {
+		name   string
+		forest string
+		want   int
+	}{
+		{"puzzle example", "30373\n25512\n65332\n33549\n35390", 8},
+		{"3x5", "30373\n25512\n65332", 2},
+		{"5x3", "325\n055\n353\n713\n323", 2},
+		{"wide", "1111111\n1121311\n1111111", 8},
+		{"tall", "111\n121\n111\n131\n111\n111\n111", 9},
+		{"single tree", "5", 0},
+		{"single row", "12345", 0},
+		{"only edges can see far", "99999\n91119\n99999", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forest, err := parseForest(tt.forest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := forest.GetMaxScenicScore(); got != tt.want {
+				t.Errorf("GetMaxScenicScore() = %v, want %v", got, tt.want)
+			}
+			if got := forest.ScenicScores().Max(); got != tt.want {
+				t.Errorf("ScenicScores().Max() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEdgeTreesScoreZero(t *testing.T) {
+	forest, err := parseForest("30373\n25512\n65332")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grid := forest.ScenicScores()
+	for i, row := range grid {
+		for j, score := range row {
+			onEdge := i == 0 || j == 0 || i == len(grid)-1 || j == len(row)-1
+			if onEdge && score != 0 {
+				t.Errorf("edge tree at %v,%v has scenic score %v, want 0", i, j, score)
+			}
+		}
+	}
+}
+
+func TestParseForest(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"valid", "303\n255\n653", ""},
+		{"trailing newlines", "303\n255\n653\n\n", ""},
+		{"non-digit cell", "303\n2a5\n653", "line 2, column 2"},
+		{"short row", "303\n25\n653", "line 2: row has 2 trees, expected 3"},
+		{"long row", "303\n255\n6531", "line 3: row has 4 trees, expected 3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forest, err := parseForest(tt.data)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(forest) != 3 || forest.NumCols() != 3 {
+					t.Errorf("parsed a %vx%v forest, want 3x3", len(forest), forest.NumCols())
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import "sort"
+
+/*
+PlacementConstraints restricts which trees are eligible for the tree house
+*/
+type PlacementConstraints struct {
+	MinHeight int
+	// # of trees between the tree and the nearest edge of the forest
+	MinEdgeDistance int
+	// Visibility must already be assigned
+	MustBeVisible bool
+}
+
+/*
+Placement is a candidate tree for the tree house
+*/
+type Placement struct {
+	Position    Position
+	Height      int
+	ScenicScore int
+}
+
+/*
+Returns whether the tree at the given position satisfies the constraints
+*/
+func (c PlacementConstraints) allows(forest Forest, p Position) bool {
+	tree := forest[p.Row][p.Col]
+	edgeDistance := p.Row
+	for _, d := range []int{p.Col, len(forest) - 1 - p.Row, len(forest[p.Row]) - 1 - p.Col} {
+		if d < edgeDistance {
+			edgeDistance = d
+		}
+	}
+	return tree.height >= c.MinHeight && edgeDistance >= c.MinEdgeDistance && (tree.visible || !c.MustBeVisible)
+}
+
+/*
+Returns the k trees w/ the highest scenic scores that satisfy the constraints, ties are ordered by position
+*/
+func (forest Forest) TopPlacements(k int, constraints PlacementConstraints) []Placement {
+	scores := forest.ScenicScores()
+	placements := []Placement{}
+	for i, row := range forest {
+		for j, tree := range row {
+			p := Position{i, j}
+			if constraints.allows(forest, p) {
+				placements = append(placements, Placement{p, tree.height, scores[i][j]})
+			}
+		}
+	}
+	sort.SliceStable(placements, func(i, j int) bool { return placements[i].ScenicScore > placements[j].ScenicScore })
+	if k < len(placements) {
+		placements = placements[:k]
+	}
+	return placements
+}
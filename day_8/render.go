@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// Characters of increasing density used to shade the heatmap
+	HEATMAP_RAMP = " .:-=+*#%@"
+	HIDDEN_TREE  = '.'
+
+	ANSI_RESET  = "\x1b[0m"
+	ANSI_HIDDEN = "\x1b[2m"
+	ANSI_BOLD   = "\x1b[1;32m"
+	// The 24 shades of the 256-color grayscale ramp start at this color index
+	ANSI_GRAYSCALE_START = 232
+	ANSI_GRAYSCALE_SIZE  = 24
+)
+
+/*
+Writes the forest as a grid of tree heights after AssignVisibility has been called
+Hidden trees are drawn as '.' unless colored is set, in which case every height is drawn and visible trees are highlighted
+*/
+func (forest Forest) RenderVisibility(w io.Writer, colored bool) error {
+	bw := bufio.NewWriter(w)
+	for _, row := range forest {
+		for _, tree := range row {
+			switch {
+			case colored && tree.visible:
+				fmt.Fprintf(bw, "%v%v%v", ANSI_BOLD, tree.height, ANSI_RESET)
+			case colored:
+				fmt.Fprintf(bw, "%v%v%v", ANSI_HIDDEN, tree.height, ANSI_RESET)
+			case tree.visible:
+				fmt.Fprint(bw, tree.height)
+			default:
+				bw.WriteRune(HIDDEN_TREE)
+			}
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+/*
+Writes the grid as a heatmap w/ one character per tree, denser characters have higher scenic scores
+If colored is set each tree is drawn as a grayscale block instead, brighter blocks have higher scenic scores
+Rows shorter than the longest row are padded w/ blanks
+*/
+func (grid ScenicScoreGrid) RenderHeatmap(w io.Writer, colored bool) error {
+	bw := bufio.NewWriter(w)
+	maxScore := grid.Max()
+	numCols := grid.numCols()
+	for _, row := range grid {
+		for _, score := range row {
+			if colored {
+				shade := ANSI_GRAYSCALE_START + heatmapLevel(score, maxScore, ANSI_GRAYSCALE_SIZE)
+				fmt.Fprintf(bw, "\x1b[48;5;%vm  %v", shade, ANSI_RESET)
+			} else {
+				bw.WriteByte(HEATMAP_RAMP[heatmapLevel(score, maxScore, len(HEATMAP_RAMP))])
+			}
+		}
+		padding := numCols - len(row)
+		if colored {
+			padding *= 2
+		}
+		bw.WriteString(strings.Repeat(" ", padding))
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+/*
+Maps a scenic score to one of numLevels levels on a log scale, scores tend to be dominated by a few trees so a linear scale
+would leave almost every tree at the lowest level
+*/
+func heatmapLevel(score int, maxScore int, numLevels int) int {
+	if maxScore <= 0 || score <= 0 {
+		return 0
+	}
+	level := int(math.Log1p(float64(score)) / math.Log1p(float64(maxScore)) * float64(numLevels-1))
+	if level >= numLevels {
+		return numLevels - 1
+	}
+	return level
+}
+
+func (grid ScenicScoreGrid) numCols() int {
+	var numCols int
+	for _, row := range grid {
+		if len(row) > numCols {
+			numCols = len(row)
+		}
+	}
+	return numCols
+}
+
+/*
+Returns an image w/ one white pixel per visible tree and one gray pixel per hidden tree, shaded by height
+Missing cells of short rows are black
+*/
+func (forest Forest) VisibilityImage() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, forest.NumCols(), len(forest)))
+	for i, row := range forest {
+		for j, tree := range row {
+			shade := uint8(32 + tree.height*12)
+			if tree.visible {
+				shade = math.MaxUint8
+			}
+			img.SetGray(j, i, color.Gray{shade})
+		}
+	}
+	return img
+}
+
+/*
+Returns an image w/ one pixel per tree, brighter pixels have higher scenic scores on the same scale as RenderHeatmap
+*/
+func (grid ScenicScoreGrid) Image() *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, grid.numCols(), len(grid)))
+	maxScore := grid.Max()
+	for i, row := range grid {
+		for j, score := range row {
+			img.SetGray(j, i, color.Gray{uint8(heatmapLevel(score, maxScore, math.MaxUint8+1))})
+		}
+	}
+	return img
+}
+
+/*
+Writes the image to disk, the format is chosen from the extension of the path: .png or .pgm
+*/
+func WriteImage(path string, img *image.Gray) error {
+	var encode func(io.Writer, *image.Gray) error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		encode = func(w io.Writer, img *image.Gray) error { return png.Encode(w, img) }
+	case ".pgm":
+		encode = encodePgm
+	default:
+		return fmt.Errorf("unknown image format for %q, expected a .png or .pgm file", path)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+/*
+Encodes the image as a binary PGM (P5) w/ 8 bits per pixel
+*/
+func encodePgm(w io.Writer, img *image.Gray) error {
+	bounds := img.Bounds()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "P5\n%v %v\n%v\n", bounds.Dx(), bounds.Dy(), math.MaxUint8)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		bw.Write(img.Pix[img.PixOffset(bounds.Min.X, y):img.PixOffset(bounds.Max.X, y)])
+	}
+	return bw.Flush()
+}
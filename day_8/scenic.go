@@ -0,0 +1,144 @@
+package main
+
+import "math"
+
+/*
+ScenicScoreGrid holds the scenic score of every tree in a forest, indexed the same way as the forest
+*/
+type ScenicScoreGrid [][]int
+
+/*
+Computes the scenic score of every tree in O(rows * cols)
+Rows may have different lengths, a row that is too short to reach a column acts as an edge of the forest for that column
+The viewing distances along each row/column are found w/ a single pass over a monotonic stack, see lookAlongLine
+Columns are transposed into rows a strip at a time so that every pass walks memory sequentially
+*/
+func (forest Forest) ScenicScores() ScenicScoreGrid {
+	numRows := len(forest)
+	if numRows == 0 {
+		return ScenicScoreGrid{}
+	}
+	numCols := forest.NumCols()
+	scores := make([]int, numRows*numCols)
+
+	// Copy heights into a flat row-major grid, cells past the end of a short row are edges
+	heights := make([]int32, numRows*numCols)
+	for i, row := range forest {
+		line := heights[i*numCols : (i+1)*numCols]
+		for j := range line {
+			line[j] = EDGE
+			if j < len(row) {
+				line[j] = int32(row[j].height)
+			}
+		}
+	}
+
+	stack := make([]blocker, 0, numCols+1)
+	for i := 0; i < numRows; i++ {
+		stack = lookAlongLine(heights[i*numCols:(i+1)*numCols], scores[i*numCols:(i+1)*numCols], stack)
+	}
+
+	stripHeights := make([]int32, COLUMN_STRIP_WIDTH*numRows)
+	stripScores := make([]int, COLUMN_STRIP_WIDTH*numRows)
+	for firstCol := 0; firstCol < numCols; firstCol += COLUMN_STRIP_WIDTH {
+		width := COLUMN_STRIP_WIDTH
+		if firstCol+width > numCols {
+			width = numCols - firstCol
+		}
+
+		// Column c of the strip is stored as row c
+		for i := 0; i < numRows; i++ {
+			line := heights[i*numCols+firstCol : i*numCols+firstCol+width]
+			for c, height := range line {
+				stripHeights[c*numRows+i] = height
+			}
+		}
+		for c := 0; c < width; c++ {
+			stack = lookAlongLine(stripHeights[c*numRows:(c+1)*numRows], stripScores[c*numRows:(c+1)*numRows], stack)
+		}
+		for i := 0; i < numRows; i++ {
+			line := scores[i*numCols+firstCol : i*numCols+firstCol+width]
+			for c := range line {
+				line[c] *= stripScores[c*numRows+i]
+			}
+		}
+	}
+
+	grid := ScenicScoreGrid{}
+	for i, row := range forest {
+		grid = append(grid, scores[i*numCols:i*numCols+len(row)])
+	}
+	return grid
+}
+
+// # of columns that are copied into rows at once, small enough for the strip being copied to stay in cache
+const COLUMN_STRIP_WIDTH = 64
+
+// Height of a missing cell, which acts as an edge of the forest
+const EDGE int32 = -1
+
+/*
+blocker is a tree on a monotonic stack, an edge of the forest is a blocker taller than any tree that sits right past the edge so
+that the distance to it is the # of trees up to the edge
+*/
+type blocker struct {
+	height   int32
+	position int32
+}
+
+/*
+Sets scores to the product of the viewing distances of every tree in both directions along the line w/ a single pass
+The stack is kept strictly decreasing so a tree is popped by the first later tree that is at least as tall, which is what blocks its
+view forwards, and the tree blocking the view backwards is either the tree left on top of the stack or a popped tree of equal height
+Scores are ints rather than int32 since the product of 2 distances overflows an int32 on rows longer than 2^16 trees
+stack is used as scratch space and returned so that it can be reused
+*/
+func lookAlongLine(heights []int32, scores []int, stack []blocker) []blocker {
+	stack = append(stack[:0], blocker{math.MaxInt32, 0})
+	for j, height := range heights {
+		if height == EDGE {
+			stack = flushLine(stack, scores, int32(j-1))
+			stack = append(stack, blocker{math.MaxInt32, int32(j + 1)})
+			continue
+		}
+
+		popped := blocker{EDGE, 0}
+		for stack[len(stack)-1].height <= height {
+			popped = stack[len(stack)-1]
+			scores[popped.position] *= j - int(popped.position)
+			stack = stack[:len(stack)-1]
+		}
+		blockedBy := stack[len(stack)-1].position
+		if popped.height == height {
+			blockedBy = popped.position
+		}
+		scores[j] = j - int(blockedBy)
+		stack = append(stack, blocker{height, int32(j)})
+	}
+	return flushLine(stack, scores, int32(len(heights)-1))
+}
+
+/*
+Trees left on the stack can see up to the edge of the forest after the last tree of the line, empties the stack
+*/
+func flushLine(stack []blocker, scores []int, last int32) []blocker {
+	for _, b := range stack[1:] {
+		scores[b.position] *= int(last - b.position)
+	}
+	return stack[:0]
+}
+
+/*
+Returns the highest scenic score in the grid
+*/
+func (grid ScenicScoreGrid) Max() int {
+	var maxScenicScore int
+	for _, row := range grid {
+		for _, score := range row {
+			if score > maxScenicScore {
+				maxScenicScore = score
+			}
+		}
+	}
+	return maxScenicScore
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+const (
+	BENCH_FOREST_SIZE = 5000
+	BENCH_NUM_HEIGHTS = 10
+	// The scan in GetMaxScenicScore is cubic on a pyramid so it gets a smaller forest
+	BENCH_PYRAMID_SIZE = 2000
+)
+
+/*
+Generates a forest w/ random tree heights in [0, numHeights)
+*/
+func randomForest(numRows int, numCols int, numHeights int, rng *rand.Rand) Forest {
+	forest := Forest{}
+	for i := 0; i < numRows; i++ {
+		row := []*Tree{}
+		for j := 0; j < numCols; j++ {
+			row = append(row, &Tree{rng.Intn(numHeights), false})
+		}
+		forest = append(forest, row)
+	}
+	return forest
+}
+
+/*
+Generates a square forest that rises towards its center, every tree looks down a monotone run to the edge of the forest in every
+direction which is the worst case for scanning
+*/
+func pyramidForest(size int) Forest {
+	forest := Forest{}
+	for i := 0; i < size; i++ {
+		row := []*Tree{}
+		for j := 0; j < size; j++ {
+			row = append(row, &Tree{min(min(i, size-1-i), min(j, size-1-j)), false})
+		}
+		forest = append(forest, row)
+	}
+	return forest
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+/*
+Computes the scenic score of a single tree by scanning in every direction
+*/
+func scanScenicScore(forest Forest, i int, j int) int {
+	height := forest[i][j].height
+	scenicScore := 1
+	for _, d := range FOUR_WAY {
+		var distance int
+		for p := (Position{i + d.DRow, j + d.DCol}); forest.Contains(p); p = (Position{p.Row + d.DRow, p.Col + d.DCol}) {
+			distance++
+			if forest[p.Row][p.Col].height >= height {
+				break
+			}
+		}
+		scenicScore *= distance
+	}
+	return scenicScore
+}
+
+func TestScenicScoresMatchScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 200; n++ {
+		forest := randomForest(1+rng.Intn(12), 1+rng.Intn(12), 1+rng.Intn(10), rng)
+		// Every other forest has ragged rows
+		if n%2 == 1 {
+			for i := range forest {
+				forest[i] = forest[i][:rng.Intn(len(forest[i])+1)]
+			}
+		}
+
+		grid := forest.ScenicScores()
+		for i, row := range forest {
+			for j := range row {
+				if want := scanScenicScore(forest, i, j); grid[i][j] != want {
+					t.Fatalf("forest %v: scenic score of %v,%v is %v, want %v", n, i, j, grid[i][j], want)
+				}
+			}
+		}
+		if got, want := forest.GetMaxScenicScore(), grid.Max(); got != want {
+			t.Fatalf("forest %v: GetMaxScenicScore() = %v, want %v", n, got, want)
+		}
+	}
+}
+
+func TestScenicScoresDontOverflow(t *testing.T) {
+	const numCols = 100001
+	forest := Forest{}
+	for i := 0; i < 3; i++ {
+		row := []*Tree{}
+		for j := 0; j < numCols; j++ {
+			row = append(row, &Tree{0, false})
+		}
+		forest = append(forest, row)
+	}
+	forest[1][numCols/2].height = 9
+
+	want := (numCols / 2) * (numCols / 2)
+	if got := forest.ScenicScores()[1][numCols/2]; got != want {
+		t.Errorf("ScenicScores()[1][%v] = %v, want %v", numCols/2, got, want)
+	}
+	if got := forest.GetMaxScenicScore(); got != want {
+		t.Errorf("GetMaxScenicScore() = %v, want %v", got, want)
+	}
+}
+
+/*
+Benchmarks are run on random heights, where most views are short, and on a pyramid, where every view runs to the edge
+*/
+func benchmarkForests() map[string]Forest {
+	return map[string]Forest{
+		"random":  randomForest(BENCH_FOREST_SIZE, BENCH_FOREST_SIZE, BENCH_NUM_HEIGHTS, rand.New(rand.NewSource(1))),
+		"pyramid": pyramidForest(BENCH_PYRAMID_SIZE),
+	}
+}
+
+func BenchmarkScenicScores(b *testing.B) {
+	for name, forest := range benchmarkForests() {
+		b.Run(name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				forest.ScenicScores().Max()
+			}
+		})
+	}
+}
+
+func BenchmarkGetMaxScenicScore(b *testing.B) {
+	for name, forest := range benchmarkForests() {
+		b.Run(name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				forest.GetMaxScenicScore()
+			}
+		})
+	}
+}
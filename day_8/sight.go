@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const DIRECTIONS_USAGE = "4, 8 or a ;-separated list of row,col steps such as 1,2;-1,2"
+
+/*
+Position of a tree in the forest, positions outside of the forest can be used as vantage points
+*/
+type Position struct {
+	Row, Col int
+}
+
+/*
+Direction is a ray w/ the given slope, steps that are not horizontal, vertical or diagonal are traced w/ Bresenham's algorithm
+*/
+type Direction struct {
+	DRow, DCol int
+}
+
+var (
+	FOUR_WAY  = []Direction{{-1, 0}, {0, 1}, {1, 0}, {0, -1}}
+	EIGHT_WAY = []Direction{{-1, 0}, {-1, 1}, {0, 1}, {1, 1}, {1, 0}, {1, -1}, {0, -1}, {-1, -1}}
+)
+
+func ParseDirections(spec string) ([]Direction, error) {
+	switch spec {
+	case "4":
+		return FOUR_WAY, nil
+	case "8":
+		return EIGHT_WAY, nil
+	}
+
+	directions := []Direction{}
+	for _, step := range strings.Split(spec, ";") {
+		dRow, dCol, found := strings.Cut(step, ",")
+		if !found {
+			return nil, fmt.Errorf("invalid direction %q, expected %v", step, DIRECTIONS_USAGE)
+		}
+		var d Direction
+		var err error
+		if d.DRow, err = strconv.Atoi(strings.TrimSpace(dRow)); err != nil {
+			return nil, fmt.Errorf("invalid direction %q: %w", step, err)
+		}
+		if d.DCol, err = strconv.Atoi(strings.TrimSpace(dCol)); err != nil {
+			return nil, fmt.Errorf("invalid direction %q: %w", step, err)
+		}
+		if d == (Direction{}) {
+			return nil, fmt.Errorf("invalid direction %q: a ray must move", step)
+		}
+		directions = append(directions, d)
+	}
+	return directions, nil
+}
+
+/*
+Sight is what can be seen from a vantage point in one direction
+Distance is the # of trees seen, the view is either blocked by the last of them or reaches the edge of the forest
+*/
+type Sight struct {
+	Direction Direction
+	Trees     []Position
+	Distance  int
+	Blocked   bool
+}
+
+/*
+Returns whether the position holds a tree, a row that is too short to reach a column acts as an edge of the forest
+*/
+func (forest Forest) Contains(p Position) bool {
+	return p.Row >= 0 && p.Row < len(forest) && p.Col >= 0 && p.Col < len(forest[p.Row])
+}
+
+/*
+Looks from the vantage point in every direction, the view in a direction is blocked by the first tree that is at least as tall as
+the vantage point
+A vantage point inside the forest doesn't see its own position, one outside the forest sees nothing until the ray enters the forest
+*/
+func (forest Forest) LineOfSight(from Position, height int, directions []Direction) []Sight {
+	sights := []Sight{}
+	for _, d := range directions {
+		sight := Sight{Direction: d}
+		forest.walkRay(from, d, func(p Position) bool {
+			sight.Trees = append(sight.Trees, p)
+			sight.Blocked = forest[p.Row][p.Col].height >= height
+			return !sight.Blocked
+		})
+		sight.Distance = len(sight.Trees)
+		sights = append(sights, sight)
+	}
+	return sights
+}
+
+/*
+Looks from the top of the tree at the given position in every direction
+*/
+func (forest Forest) LineOfSightFromTree(from Position, directions []Direction) ([]Sight, error) {
+	if !forest.Contains(from) {
+		return nil, fmt.Errorf("there is no tree at row %v, column %v", from.Row, from.Col)
+	}
+	return forest.LineOfSight(from, forest[from.Row][from.Col].height, directions), nil
+}
+
+/*
+Assigns visibility value to each tree when looking into the forest from outside along the given directions
+A tree is visible if it is taller than any tree between it and the edge of the forest, so looking along FOUR_WAY is equivalent
+to AssignVisibility
+*/
+func (forest Forest) AssignVisibilityAlong(directions []Direction) {
+	for i, row := range forest {
+		for j, tree := range row {
+			tree.visible = false
+			for _, sight := range forest.LineOfSight(Position{i, j}, tree.height, directions) {
+				if !sight.Blocked {
+					tree.visible = true
+					break
+				}
+			}
+		}
+	}
+}
+
+/*
+Returns the highest scenic score looking along the given directions, the scenic score of a tree is the product of its viewing
+distances so looking along FOUR_WAY is equivalent to GetMaxScenicScore
+*/
+func (forest Forest) GetMaxScenicScoreAlong(directions []Direction) int {
+	var maxScenicScore int
+	for i, row := range forest {
+		for j, tree := range row {
+			scenicScore := 1
+			for _, sight := range forest.LineOfSight(Position{i, j}, tree.height, directions) {
+				scenicScore *= sight.Distance
+			}
+			if scenicScore > maxScenicScore {
+				maxScenicScore = scenicScore
+			}
+		}
+	}
+	return maxScenicScore
+}
+
+/*
+Calls visit for every tree along the ray until it returns false or the ray leaves the forest
+The ray is traced w/ Bresenham's algorithm so every step moves to one of the 8 neighbouring positions
+*/
+func (forest Forest) walkRay(from Position, d Direction, visit func(Position) bool) {
+	if d == (Direction{}) {
+		return
+	}
+	dRow, dCol := abs(d.DRow), abs(d.DCol)
+	stepRow, stepCol := sign(d.DRow), sign(d.DCol)
+	numRows, numCols := len(forest), forest.NumCols()
+
+	entered := forest.Contains(from)
+	p := from
+	err := dCol - dRow
+	for {
+		e2 := 2 * err
+		if e2 > -dRow {
+			err -= dRow
+			p.Col += stepCol
+		}
+		if e2 < dCol {
+			err += dCol
+			p.Row += stepRow
+		}
+
+		if forest.Contains(p) {
+			entered = true
+			if !visit(p) {
+				return
+			}
+			continue
+		}
+		// Outside of the forest, stop once the ray has left it or can no longer reach it
+		if entered ||
+			(p.Row < 0 && stepRow <= 0) || (p.Row >= numRows && stepRow >= 0) ||
+			(p.Col < 0 && stepCol <= 0) || (p.Col >= numCols && stepCol >= 0) {
+			return
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"encoding/binary"
+	"flag"
+	"fmt"
 	"log"
-	"math"
 	"os"
 	"strconv"
 	"strings"
@@ -16,58 +18,184 @@ const (
 )
 
 func main() {
-	instructions := openInputFile()
-	log.Printf("Part 1: The tail of the rope visited %v unique positions", DetermineNumUniqueTailPositions(instructions, 2))
+	allKnots := flag.Bool("all-knots", false, "also report the # of unique positions visited by every knot of the 10 knot rope")
+	followSpec := flag.String("follow", "chebyshev", "how knots follow the knot in front of them, one of: "+FOLLOW_RULE_USAGE)
+	trace := flag.Bool("trace", false, "print the rope after every instruction instead of solving the puzzle")
+	trail := flag.Bool("trail", false, "w/ -trace, also print every position visited by the tail")
+	numKnots := flag.Int("knots", 10, "w/ -trace, # of knots in the rope")
+	width := flag.Int("width", 26, "w/ -trace, width of the viewport that follows the head")
+	height := flag.Int("height", 21, "w/ -trace, height of the viewport that follows the head")
+	dims := flag.Int("dims", 2, "# of dimensions the rope moves in, directions may also use "+DIRECTION_USAGE)
+	input := flag.String("input", "resources/input", "input file w/ one \"direction moves\" instruction per line")
+	flag.Parse()
+
+	rule, err := ParseFollowRule(*followSpec)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *dims < 1 {
+		log.Fatalf("the rope needs at least 1 dimension, got %v", *dims)
+	}
+
+	instructions, err := openInputFile(*input, *dims)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *trace {
+		if *numKnots < 1 || *width < 1 || *height < 1 {
+			log.Fatal("the rope needs at least 1 knot and the viewport at least 1 cell")
+		}
+		visited, err := TraceRope(os.Stdout, NewRopeWithRule(*numKnots, *dims, rule), instructions, NewViewport(*width, *height))
+		if err != nil {
+			log.Fatal(err)
+		}
+		if *trail {
+			if err := RenderVisited(os.Stdout, visited.Projected(0)); err != nil {
+				log.Fatal(err)
+			}
+		}
+		return
+	}
+
+	log.Printf("Part 1: The tail of the rope visited %v unique positions", DetermineNumUniqueTailPositions(instructions, 2, *dims, rule))
+
+	// Part 2 and -all-knots share a single run of the 10 knot rope
+	knots := []int{9}
+	if *allKnots {
+		knots = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	}
+	counts := DetermineNumUniqueKnotPositions(instructions, 10, *dims, rule, knots)
 	// 6242 too high
-	log.Printf("Part 2: The tail of the rope visited %v unique positions", DetermineNumUniqueTailPositions(instructions, 10))
+	log.Printf("Part 2: The tail of the rope visited %v unique positions", counts[len(knots)-1])
+	if *allKnots {
+		for i, numUniquePositions := range counts {
+			log.Printf("Knot %v of the rope visited %v unique positions", i, numUniquePositions)
+		}
+	}
 }
 
-func DetermineNumUniqueTailPositions(instructions []Instruction, numKnots int) int {
-	initialKnots := make([]KnotPosition, numKnots)
-	currentRope := RopePosition{numKnots, initialKnots}
-	ropeHistory := []RopePosition{currentRope}
+func DetermineNumUniqueTailPositions(instructions []Instruction, numKnots int, dims int, rule FollowRule) int {
+	return DetermineNumUniqueKnotPositions(instructions, numKnots, dims, rule, []int{numKnots - 1})[0]
+}
+
+/*
+Returns the # of unique positions visited by each of the given knots, the head is knot 0
+The rope is moved in place and only the given knots are tracked so memory only grows w/ the # of positions they visit
+*/
+func DetermineNumUniqueKnotPositions(instructions []Instruction, numKnots int, dims int, rule FollowRule, knots []int) []int {
+	rope := NewRopeWithRule(numKnots, dims, rule)
+	visited := NewVisitedPositions(knots)
+	visited.Record(rope)
 	for _, instruction := range instructions {
-		currentRope = currentRope.MoveRope(instruction)
-		ropeHistory = append(ropeHistory, currentRope)
-	}
-
-	var numUniquePositions int
-	empty := struct{}{}
-	tailHistory := map[KnotPosition]struct{}{}
-	for _, rope := range ropeHistory {
-		tail := rope.knotPositions[rope.numKnots-1]
-		_, exists := tailHistory[tail]
-		if !exists {
-			numUniquePositions++
-			tailHistory[tail] = empty
+		rope.MoveRope(instruction, visited.Record)
+	}
+	return visited.Counts()
+}
+
+/*
+VisitedPositions holds the set of positions visited by some of the knots of a rope in any # of dimensions
+Positions are keyed by their coordinates encoded as varints, see positionKey
+*/
+type VisitedPositions struct {
+	// Indices of the tracked knots, positions[i] holds the positions visited by knot knots[i]
+	knots     []int
+	positions []map[string]struct{}
+	key       []byte
+}
+
+/*
+Returns visited positions that track the given knots of a rope
+*/
+func NewVisitedPositions(knots []int) *VisitedPositions {
+	visited := &VisitedPositions{knots: knots, positions: make([]map[string]struct{}, len(knots))}
+	for i := range visited.positions {
+		visited.positions[i] = map[string]struct{}{}
+	}
+	return visited
+}
+
+/*
+Records the current position of every tracked knot of the rope
+*/
+func (visited *VisitedPositions) Record(rope *RopePosition) {
+	for i, positions := range visited.positions {
+		visited.key = positionKey(visited.key, rope.Knot(visited.knots[i]))
+		// Looking the key up first avoids allocating a string for positions that were already visited
+		if _, found := positions[string(visited.key)]; !found {
+			positions[string(visited.key)] = struct{}{}
 		}
 	}
-	return numUniquePositions
 }
 
 /*
-Parse input file for AOC 2022 day_9 challenge
+Returns the # of unique positions visited by each tracked knot, in the order the knots were given
+*/
+func (visited *VisitedPositions) Counts() []int {
+	counts := []int{}
+	for _, positions := range visited.positions {
+		counts = append(counts, len(positions))
+	}
+	return counts
+}
+
+/*
+Returns the positions visited by the i-th tracked knot projected onto the first 2 axes
 */
-func openInputFile() []Instruction {
-	data, err := os.ReadFile("resources/input")
+func (visited *VisitedPositions) Projected(i int) map[KnotPosition]struct{} {
+	projected := map[KnotPosition]struct{}{}
+	for key := range visited.positions[i] {
+		projected[project(decodePositionKey(key))] = struct{}{}
+	}
+	return projected
+}
+
+/*
+Encodes the coordinates of a knot into key, which is reused to avoid allocating
+*/
+func positionKey(key []byte, knot []int) []byte {
+	key = key[:0]
+	for _, coordinate := range knot {
+		key = binary.AppendVarint(key, int64(coordinate))
+	}
+	return key
+}
+
+func decodePositionKey(key string) []int {
+	knot := []int{}
+	for buf := []byte(key); len(buf) > 0; {
+		coordinate, n := binary.Varint(buf)
+		knot = append(knot, int(coordinate))
+		buf = buf[n:]
+	}
+	return knot
+}
+
+/*
+Parse input file for AOC 2022 day_9 challenge, directions must be valid for a rope in dims dimensions, see ParseDirection
+*/
+func openInputFile(path string, dims int) ([]Instruction, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	result := []Instruction{}
-	splitLines := strings.Split(string(data), "\n")
-	for _, line := range splitLines {
-		splitLine := strings.Split(line, " ")
-		moves, _ := strconv.Atoi(splitLine[1])
-		/*
-		 explode each move to its own step
-		 this makes things a bit simpler but might be more costly performance-wise compared to range-based solutions
-		*/
-		for i := 0; i < moves; i++ {
-			result = append(result, Instruction{splitLine[0], 1})
+	splitLines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	for i, line := range splitLines {
+		direction, movesStr, found := strings.Cut(line, " ")
+		if !found {
+			return nil, fmt.Errorf("line %v: expected a direction and a # of moves, got %q", i+1, line)
+		}
+		if _, err := ParseDirection(direction, dims); err != nil {
+			return nil, fmt.Errorf("line %v: %w", i+1, err)
+		}
+		moves, err := strconv.Atoi(movesStr)
+		if err != nil || moves < 0 {
+			return nil, fmt.Errorf("line %v: invalid # of moves %q", i+1, movesStr)
 		}
+		result = append(result, Instruction{direction, moves})
 	}
-	return result
+	return result, nil
 }
 
 type Instruction struct {
@@ -75,72 +203,72 @@ type Instruction struct {
 	moves     int
 }
 
+/*
+RopePosition is a rope in dims dimensions, the puzzle's rope moves in 2
+*/
 type RopePosition struct {
-	numKnots      int
-	knotPositions []KnotPosition
+	numKnots int
+	dims     int
+	// Coordinates of every knot, one entry per axis, see Knot
+	coords []int
+	rule   FollowRule
 }
 
 /*
-Moves rope based on directions provided by input
-Returns head and tail position after move
+Returns a rope in dims dimensions w/ every knot at the origin that follows the given rule
 */
-func (rope RopePosition) MoveRope(instruction Instruction) RopePosition {
-	// move head
-	head := rope.knotPositions[0].MoveKnot(instruction)
-	newPositions := []KnotPosition{head}
-
-	// move all other knots
-	for i := 1; i < rope.numKnots; i++ {
-		tail := rope.knotPositions[i]
-		tail = tail.MakeAdjacentTo(head)
-		newPositions = append(newPositions, tail)
-		head = tail
-	}
-	return RopePosition{rope.numKnots, newPositions}
-}
-
-type KnotPosition struct {
-	x int
-	y int
+func NewRopeWithRule(numKnots int, dims int, rule FollowRule) *RopePosition {
+	return &RopePosition{numKnots, dims, make([]int, numKnots*dims), rule}
 }
 
-func (knot KnotPosition) MoveKnot(instruction Instruction) KnotPosition {
-	var newKnot KnotPosition
-	switch instruction.direction {
-	case RIGHT:
-		newKnot = KnotPosition{knot.x + instruction.moves, knot.y}
-	case LEFT:
-		newKnot = KnotPosition{knot.x - instruction.moves, knot.y}
-	case UP:
-		newKnot = KnotPosition{knot.x, knot.y + instruction.moves}
-	case DOWN:
-		newKnot = KnotPosition{knot.x, knot.y - instruction.moves}
-	}
-	return newKnot
+/*
+Returns the coordinates of knot i, changing them moves the knot
+*/
+func (rope *RopePosition) Knot(i int) []int {
+	return rope.coords[i*rope.dims : (i+1)*rope.dims : (i+1)*rope.dims]
 }
 
-func (knot KnotPosition) IsAdjacent(otherKnot KnotPosition) bool {
-	return math.Abs(float64(knot.x-otherKnot.x)) <= 1 && math.Abs(float64(knot.y-otherKnot.y)) <= 1
+/*
+Returns knot i projected onto the first 2 axes
+*/
+func (rope *RopePosition) KnotPosition(i int) KnotPosition {
+	return project(rope.Knot(i))
 }
 
-func (knot KnotPosition) MakeAdjacentTo(otherKnot KnotPosition) KnotPosition {
-	if knot.IsAdjacent(otherKnot) {
-		return knot
+/*
+Moves rope in place based on directions provided by input, one step at a time
+visit is called after every step and may be nil
+*/
+func (rope *RopePosition) MoveRope(instruction Instruction, visit func(rope *RopePosition)) {
+	step, err := ParseDirection(instruction.direction, rope.dims)
+	if err != nil {
+		// Unknown directions leave the rope in place, see ParseDirection
+		return
 	}
-	dX := otherKnot.x - knot.x
-	dY := otherKnot.y - knot.y
+	head := rope.Knot(0)
+	for n := 0; n < instruction.moves; n++ {
+		// move head
+		for axis, d := range step {
+			head[axis] += d
+		}
 
-	if dX < 0 {
-		dX = int(math.Max(float64(dX), -1))
-	} else {
-		dX = int(math.Min(float64(dX), 1))
-	}
+		// move all other knots, once a knot stays put so does the rest of the rope
+		for i := 1; i < rope.numKnots; i++ {
+			if !rope.rule.Follow(rope.Knot(i), rope.Knot(i-1)) {
+				break
+			}
+		}
 
-	if dY < 0 {
-		dY = int(math.Max(float64(dY), -1))
-	} else {
-		dY = int(math.Min(float64(dY), 1))
+		if visit != nil {
+			visit(rope)
+		}
 	}
+}
 
-	return KnotPosition{knot.x + dX, knot.y + dY}
+/*
+KnotPosition is a knot projected onto the first 2 axes, which is the plane that gets rendered
+*/
+type KnotPosition struct {
+	x int
+	y int
 }
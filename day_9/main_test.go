@@ -0,0 +1,147 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Examples from the puzzle
+const (
+	EXAMPLE_INSTRUCTIONS = "R 4\nU 4\nL 3\nD 1\nR 4\nD 1\nL 5\nR 2"
+	LARGER_EXAMPLE       = "R 5\nU 8\nL 8\nD 3\nR 17\nD 10\nL 25\nU 20"
+)
+
+/*
+Writes the instructions to a temporary input file and parses them for a rope in dims dimensions
+*/
+func parseInstructions(t *testing.T, instructions string, dims int) ([]Instruction, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "input")
+	if err := os.WriteFile(path, []byte(instructions), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return openInputFile(path, dims)
+}
+
+func TestDetermineNumUniqueTailPositions(t *testing.T) {
+	// The examples w/ U/D moved onto the 3rd axis, which must not change the answers
+	examplesAlong3rdAxis := strings.NewReplacer("U", FORWARD, "D", BACK)
+
+	tests := []struct {
+		name         string
+		instructions string
+		dims         int
+		follow       string
+		numKnots     int
+		want         int
+	}{
+		{"puzzle example", EXAMPLE_INSTRUCTIONS, 2, "chebyshev", 2, 13},
+		{"puzzle example w/ 10 knots", EXAMPLE_INSTRUCTIONS, 2, "chebyshev", 10, 1},
+		{"larger example", LARGER_EXAMPLE, 2, "chebyshev", 10, 36},
+		{"puzzle example in 3D", EXAMPLE_INSTRUCTIONS, 3, "chebyshev", 2, 13},
+		{"puzzle example along the 3rd axis", examplesAlong3rdAxis.Replace(EXAMPLE_INSTRUCTIONS), 3, "chebyshev", 2, 13},
+		{"larger example along the 3rd axis", examplesAlong3rdAxis.Replace(LARGER_EXAMPLE), 3, "chebyshev", 10, 36},
+		{"larger example along axis 3 of 4", strings.NewReplacer("R", "+0", "L", "-0", "U", "+3", "D", "-3").Replace(LARGER_EXAMPLE), 4, "chebyshev", 10, 36},
+		{"diagonal", "UR 3", 2, "chebyshev", 2, 3},
+		{"diagonal and back", "UR 3\nDL 5", 2, "chebyshev", 2, 4},
+		{"3D diagonal", "R 4\nF 4", 3, "chebyshev", 2, 7},
+		{"3D space diagonal", "URF 4", 3, "chebyshev", 3, 3},
+		{"manhattan never moves diagonally", "R 1\nU 2", 2, "manhattan", 2, 3},
+		{"chebyshev moves diagonally", "R 1\nU 2", 2, "chebyshev", 2, 2},
+		{"manhattan in 3D", "R 1\nU 1\nF 2", 3, "manhattan", 2, 4},
+		{"slack", "R 5", 2, "slack:2", 2, 4},
+		{"slack of 1", LARGER_EXAMPLE, 2, "slack:1", 10, 36},
+		{"elastic", "R 5", 2, "elastic:2", 2, 3},
+		{"elastic diagonal", "R 1\nU 3", 2, "elastic:2", 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instructions, err := parseInstructions(t, tt.instructions, tt.dims)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rule, err := ParseFollowRule(tt.follow)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := DetermineNumUniqueTailPositions(instructions, tt.numKnots, tt.dims, rule); got != tt.want {
+				t.Errorf("DetermineNumUniqueTailPositions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetermineNumUniqueKnotPositions(t *testing.T) {
+	instructions, err := parseInstructions(t, EXAMPLE_INSTRUCTIONS, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the given knots are counted, in the order they were given
+	got := DetermineNumUniqueKnotPositions(instructions, 10, 2, ChebyshevRule{}, []int{9, 1, 0})
+	if want := []int{1, 13, 21}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DetermineNumUniqueKnotPositions() = %v, want %v", got, want)
+	}
+}
+
+func TestParseDirection(t *testing.T) {
+	tests := []struct {
+		direction string
+		dims      int
+		want      []int
+		wantErr   string
+	}{
+		{RIGHT, 2, []int{1, 0}, ""},
+		{DOWN, 2, []int{0, -1}, ""},
+		{"DL", 2, []int{-1, -1}, ""},
+		{"UR", 3, []int{1, 1, 0}, ""},
+		{"UF", 3, []int{0, 1, 1}, ""},
+		{BACK, 3, []int{0, 0, -1}, ""},
+		{"+0", 1, []int{1}, ""},
+		{"-3", 4, []int{0, 0, 0, -1}, ""},
+		{"", 2, nil, `invalid direction ""`},
+		{"X", 2, nil, `unknown letter 'X'`},
+		{"F", 2, nil, `'F' needs at least 3 dimensions`},
+		{"U", 1, nil, `'U' needs at least 2 dimensions`},
+		{"UD", 2, nil, "axis 1 is used more than once"},
+		{"RR", 2, nil, "axis 0 is used more than once"},
+		{"+2", 2, nil, "expected an axis between 0 and 1"},
+		{"-x", 2, nil, "expected an axis between 0 and 1"},
+		{"+", 2, nil, "expected an axis between 0 and 1"},
+		{"--1", 2, nil, "expected an axis between 0 and 1"},
+	}
+	for _, tt := range tests {
+		step, err := ParseDirection(tt.direction, tt.dims)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseDirection(%q, %v) error = %v, want it to contain %q", tt.direction, tt.dims, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseDirection(%q, %v): %v", tt.direction, tt.dims, err)
+		} else if !reflect.DeepEqual(step, tt.want) {
+			t.Errorf("ParseDirection(%q, %v) = %v, want %v", tt.direction, tt.dims, step, tt.want)
+		}
+	}
+}
+
+func TestOpenInputFileErrors(t *testing.T) {
+	tests := []struct {
+		instructions string
+		dims         int
+		wantErr      string
+	}{
+		{"R 4\nR", 2, "line 2: expected a direction and a # of moves"},
+		{"R 4\nF 1", 2, `line 2: invalid direction "F"`},
+		{"R x", 2, `line 1: invalid # of moves "x"`},
+		{"R -1", 2, `line 1: invalid # of moves "-1"`},
+	}
+	for _, tt := range tests {
+		if _, err := parseInstructions(t, tt.instructions, tt.dims); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("openInputFile(%q) error = %v, want it to contain %q", tt.instructions, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	FORWARD = "F"
+	BACK    = "B"
+
+	DIRECTION_USAGE = "F/B along the 3rd axis, combinations of letters such as UF, or +k/-k for a unit step along axis k"
+)
+
+/*
+Unit steps along the first 3 axes, letters can be combined into diagonal directions such as "UR" or "UF"
+*/
+var axisSteps = map[string]struct{ axis, step int }{
+	RIGHT: {0, 1}, LEFT: {0, -1},
+	UP: {1, 1}, DOWN: {1, -1},
+	FORWARD: {2, 1}, BACK: {2, -1},
+}
+
+/*
+Parses a direction into a unit step of a rope in dims dimensions, see DIRECTION_USAGE
+The puzzle's directions are the 2 dimensional ones: R/L along the 1st axis, U/D along the 2nd and their diagonals
+*/
+func ParseDirection(direction string, dims int) ([]int, error) {
+	step := make([]int, dims)
+	if strings.HasPrefix(direction, "+") || strings.HasPrefix(direction, "-") {
+		axis, err := strconv.Atoi(direction[1:])
+		if err != nil || axis < 0 || axis >= dims {
+			return nil, fmt.Errorf("invalid direction %q, expected an axis between 0 and %v", direction, dims-1)
+		}
+		step[axis] = 1
+		if direction[0] == '-' {
+			step[axis] = -1
+		}
+		return step, nil
+	}
+
+	if direction == "" {
+		return nil, fmt.Errorf("invalid direction %q", direction)
+	}
+	for _, letter := range direction {
+		s, found := axisSteps[string(letter)]
+		if !found {
+			return nil, fmt.Errorf("invalid direction %q, unknown letter %q", direction, letter)
+		}
+		if s.axis >= dims {
+			return nil, fmt.Errorf("invalid direction %q, %q needs at least %v dimensions", direction, letter, s.axis+1)
+		}
+		if step[s.axis] != 0 {
+			return nil, fmt.Errorf("invalid direction %q, axis %v is used more than once", direction, s.axis)
+		}
+		step[s.axis] = s.step
+	}
+	return step, nil
+}
+
+/*
+Projects the coordinates of a knot onto the first 2 axes, which is the plane that gets rendered
+*/
+func project(knot []int) KnotPosition {
+	var p KnotPosition
+	if len(knot) > 0 {
+		p.x = knot[0]
+	}
+	if len(knot) > 1 {
+		p.y = knot[1]
+	}
+	return p
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	EMPTY_CELL   = '.'
+	START_CELL   = 's'
+	VISITED_CELL = '#'
+)
+
+/*
+Viewport is the part of the plane that is drawn, x grows to the right and y grows upwards
+*/
+type Viewport struct {
+	minX, minY    int
+	width, height int
+}
+
+/*
+Returns a viewport of the given size w/ the origin in its center
+*/
+func NewViewport(width int, height int) *Viewport {
+	return &Viewport{-width / 2, -height / 2, width, height}
+}
+
+/*
+Pans the viewport as little as possible so that it contains the knot
+*/
+func (v *Viewport) Follow(knot KnotPosition) {
+	if knot.x < v.minX {
+		v.minX = knot.x
+	} else if knot.x >= v.minX+v.width {
+		v.minX = knot.x - v.width + 1
+	}
+	if knot.y < v.minY {
+		v.minY = knot.y
+	} else if knot.y >= v.minY+v.height {
+		v.minY = knot.y - v.height + 1
+	}
+}
+
+/*
+Returns the label of a knot in the style of the puzzle: the head is H, the tail of a 2 knot rope is T and every other knot is its
+index, knots past the 9th are drawn as '*'
+*/
+func knotLabel(i int, numKnots int) byte {
+	switch {
+	case i == 0:
+		return 'H'
+	case numKnots == 2:
+		return 'T'
+	case i < 10:
+		return strconv.Itoa(i)[0]
+	}
+	return '*'
+}
+
+/*
+Writes the part of the rope inside the viewport, knots closer to the head are drawn on top of the ones behind them
+Ropes in more than 2 dimensions are projected onto the first 2 axes
+The starting position is drawn as 's' unless a knot covers it
+*/
+func (rope *RopePosition) Render(w io.Writer, v *Viewport) error {
+	cells := make([][]byte, v.height)
+	for row := range cells {
+		cells[row] = make([]byte, v.width)
+		for col := range cells[row] {
+			cells[row][col] = EMPTY_CELL
+		}
+	}
+	set := func(knot KnotPosition, label byte) {
+		col, row := knot.x-v.minX, v.minY+v.height-1-knot.y
+		if col >= 0 && col < v.width && row >= 0 && row < v.height {
+			cells[row][col] = label
+		}
+	}
+
+	set(KnotPosition{}, START_CELL)
+	for i := rope.numKnots - 1; i >= 0; i-- {
+		set(rope.KnotPosition(i), knotLabel(i, rope.numKnots))
+	}
+	return writeCells(w, cells)
+}
+
+/*
+Writes every position visited by a knot as '#' on the smallest map that contains all of them, the starting position is drawn as 's'
+*/
+func RenderVisited(w io.Writer, positions map[KnotPosition]struct{}) error {
+	start := KnotPosition{}
+	minX, maxX, minY, maxY := start.x, start.x, start.y, start.y
+	for p := range positions {
+		minX, maxX = min(minX, p.x), max(maxX, p.x)
+		minY, maxY = min(minY, p.y), max(maxY, p.y)
+	}
+
+	cells := [][]byte{}
+	for y := maxY; y >= minY; y-- {
+		row := []byte{}
+		for x := minX; x <= maxX; x++ {
+			p := KnotPosition{x, y}
+			_, visited := positions[p]
+			switch {
+			case p == start:
+				row = append(row, START_CELL)
+			case visited:
+				row = append(row, VISITED_CELL)
+			default:
+				row = append(row, EMPTY_CELL)
+			}
+		}
+		cells = append(cells, row)
+	}
+	return writeCells(w, cells)
+}
+
+/*
+Moves the rope through the instructions, writing the rope after every instruction in the style of the puzzle's examples
+Returns the positions visited by the tail
+*/
+func TraceRope(w io.Writer, rope *RopePosition, instructions []Instruction, v *Viewport) (*VisitedPositions, error) {
+	visited := NewVisitedPositions([]int{rope.numKnots - 1})
+	visited.Record(rope)
+	for _, instruction := range instructions {
+		rope.MoveRope(instruction, visited.Record)
+		v.Follow(rope.KnotPosition(0))
+		if _, err := fmt.Fprintf(w, "== %v %v ==\n\n", instruction.direction, instruction.moves); err != nil {
+			return nil, err
+		}
+		if err := rope.Render(w, v); err != nil {
+			return nil, err
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return nil, err
+		}
+	}
+	return visited, nil
+}
+
+func writeCells(w io.Writer, cells [][]byte) error {
+	bw := bufio.NewWriter(w)
+	for _, row := range cells {
+		bw.Write(row)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
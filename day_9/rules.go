@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const FOLLOW_RULE_USAGE = "chebyshev, manhattan, slack:k or elastic:k"
+
+/*
+FollowRule determines where a knot moves to after the knot in front of it (the leader) has moved
+Knots are given as their coordinates w/ one entry per axis so that every rule works in any # of dimensions
+Follow moves the knot in place and returns whether it moved, it must only depend on its arguments, a knot that doesn't move leaves
+the rest of the rope in place
+*/
+type FollowRule interface {
+	Follow(knot []int, leader []int) bool
+}
+
+/*
+Knots stay in any of the cells around their leader (or on top of it), i.e. within a Chebyshev distance of 1, and otherwise step
+towards it, possibly diagonally
+In 2 dimensions that is any of the 8 cells around the leader, in 3 dimensions any of the 26
+*/
+type ChebyshevRule struct{}
+
+func (ChebyshevRule) Follow(knot []int, leader []int) bool {
+	return SlackRule{1}.Follow(knot, leader)
+}
+
+/*
+Knots stay in one of the cells that share a side w/ their leader (or on top of it) and otherwise step towards it along the axis
+it is furthest away on until it is next to it again, so knots never move diagonally
+*/
+type ManhattanRule struct{}
+
+func (ManhattanRule) Follow(knot []int, leader []int) bool {
+	moved := false
+	for {
+		var distance, furthest int
+		for axis := range knot {
+			distance += abs(leader[axis] - knot[axis])
+			if abs(leader[axis]-knot[axis]) > abs(leader[furthest]-knot[furthest]) {
+				furthest = axis
+			}
+		}
+		if distance <= 1 {
+			return moved
+		}
+		knot[furthest] += sign(leader[furthest] - knot[furthest])
+		moved = true
+	}
+}
+
+/*
+Knots stay within k cells of their leader along every axis and otherwise step towards it, possibly diagonally
+A slack of 1 is equivalent to ChebyshevRule
+*/
+type SlackRule struct {
+	k int
+}
+
+func (rule SlackRule) Follow(knot []int, leader []int) bool {
+	if chebyshevDistance(knot, leader) <= rule.k {
+		return false
+	}
+	for axis := range knot {
+		knot[axis] += sign(leader[axis] - knot[axis])
+	}
+	return true
+}
+
+/*
+Knots stay put until the rope between them and their leader is stretched beyond k cells, after which they snap back next to their
+leader on the side they were pulled from
+*/
+type ElasticRule struct {
+	k int
+}
+
+func (rule ElasticRule) Follow(knot []int, leader []int) bool {
+	if chebyshevDistance(knot, leader) <= rule.k {
+		return false
+	}
+	for axis := range knot {
+		knot[axis] = leader[axis] - sign(leader[axis]-knot[axis])
+	}
+	return true
+}
+
+/*
+Returns the largest distance between the knots along any axis
+*/
+func chebyshevDistance(knot []int, otherKnot []int) int {
+	var distance int
+	for axis := range knot {
+		distance = max(distance, abs(otherKnot[axis]-knot[axis]))
+	}
+	return distance
+}
+
+/*
+Parses a follow rule such as "slack:2", see FOLLOW_RULE_USAGE
+*/
+func ParseFollowRule(spec string) (FollowRule, error) {
+	name, arg, hasArg := strings.Cut(spec, ":")
+	switch name {
+	case "chebyshev", "manhattan":
+		if hasArg {
+			return nil, fmt.Errorf("follow rule %q takes no argument", name)
+		}
+		if name == "chebyshev" {
+			return ChebyshevRule{}, nil
+		}
+		return ManhattanRule{}, nil
+	case "slack", "elastic":
+		k, err := strconv.Atoi(arg)
+		if err != nil || k < 1 {
+			return nil, fmt.Errorf("follow rule %q needs a slack of at least 1 cell, e.g. %v:2", spec, name)
+		}
+		if name == "slack" {
+			return SlackRule{k}, nil
+		}
+		return ElasticRule{k}, nil
+	}
+	return nil, fmt.Errorf("unknown follow rule %q, expected one of: %v", spec, FOLLOW_RULE_USAGE)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}